@@ -0,0 +1,129 @@
+// Package book holds the graded reader book format shared by every text in
+// the library, together with the converters that build it from the plain
+// text sources.
+package book
+
+import (
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// Book is one converted text. SchemaVersion records the version of the
+// format the file was written in; see Migrate.
+type Book struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Title         string `json:"title"`
+	Slug          string `json:"slug"`
+	Author        string `json:"author"`
+	Language      string `json:"language"`
+	Description   string `json:"description"`
+	CoverImage    string `json:"coverImage,omitempty"`
+	// Thumbnails are smaller copies of the cover; see AddCover.
+	Thumbnails []Thumbnail `json:"thumbnails,omitempty"`
+	// Restricted marks a book the app should not offer to every reader.
+	Restricted bool       `json:"restricted,omitempty"`
+	Chapters   []*Chapter `json:"chapters"`
+	// Parts outlines the book above its chapters; see Part.
+	Parts []*Part `json:"parts,omitempty"`
+	// Vocab is the combined vocabulary of a work made of volumes: each
+	// word glossed in their chapters, once.
+	Vocab []VocabItem `json:"vocab,omitempty"`
+
+	// Speakers lists the dramatis personae of a dialogue, and Stephanus
+	// indexes the Stephanus sections of a Plato dialogue.
+	Speakers  []Speaker         `json:"speakers,omitempty"`
+	Stephanus []StephanusAnchor `json:"stephanus,omitempty"`
+}
+
+type Chapter struct {
+	Slug  string `json:"slug"`
+	Title Title  `json:"title"`
+	// Number is the number a chapter's title gives it in Greek numerals,
+	// as 1 for "Ἐντολὴ αʹ", the first of the Shepherd's Mandates.
+	Number     int           `json:"number,omitempty"`
+	TitleImage string        `json:"titleImage"`
+	Vocab      []VocabItem   `json:"vocab"`
+	Questions  []Question    `json:"questions"`
+	Content    []ContentItem `json:"content"`
+}
+
+type Title struct {
+	Display string `json:"display"`
+	Gloss   string `json:"gloss"`
+}
+
+type VocabItem struct {
+	Word  string `json:"word"`
+	Gloss string `json:"gloss"`
+	Image string `json:"image"`
+}
+
+type Question struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// ContentItem groups verses for display. In a dialogue each item is one
+// speaking turn and Speaker holds the speaker's abbreviation.
+type ContentItem struct {
+	Subtitle  string      `json:"subtitle,omitempty"`
+	Image     string      `json:"image,omitempty"`
+	Speaker   string      `json:"speaker,omitempty"`
+	Paragraph []Paragraph `json:"paragraph"`
+}
+
+// Paragraph is one verse. VerseID numbers the verses of a book in reading
+// order, starting at 1; Ref is the verse's citation in the source,
+// Stephanus the Stephanus section in which the verse begins and Speaker
+// the abbreviation of who speaks it.
+type Paragraph struct {
+	VerseID   int    `json:"verse_id"`
+	Ref       *Ref   `json:"ref,omitempty"`
+	Stephanus string `json:"stephanus,omitempty"`
+	Speaker   string `json:"speaker,omitempty"`
+	Words     []Word `json:"words"`
+}
+
+// Word is one word of a verse. Word holds the clean form used for gloss
+// lookups, with its punctuation split off into Pre and Post and any elision
+// mark written as ’; Elision keeps the mark the word was written with when
+// it differed, as in words migrated from older files. Stephanus is set on
+// the word at which a Stephanus section begins, for use as a margin
+// reference.
+type Word struct {
+	Word      string  `json:"word"`
+	Gloss     string  `json:"gloss"`
+	Pre       string  `json:"pre,omitempty"`
+	Post      string  `json:"post,omitempty"`
+	Elision   string  `json:"elision,omitempty"`
+	Stephanus string  `json:"stephanus,omitempty"`
+	Markup    *Markup `json:"markup,omitempty"`
+}
+
+// Written returns the word with its punctuation, as the source wrote it.
+func (w Word) Written() string {
+	t := greek.Token{Pre: w.Pre, Form: w.Word, Elision: w.Elision, Post: w.Post}
+	return t.String()
+}
+
+// Text renders the words of a verse back into its text.
+func (p Paragraph) Text() string {
+	words := make([]string, len(p.Words))
+	for i, w := range p.Words {
+		words[i] = w.Written()
+	}
+	return strings.Join(words, " ")
+}
+
+// NewChapter returns an empty chapter with the given slug and display title.
+func NewChapter(slug, title string) *Chapter {
+	return &Chapter{
+		Slug:       slug,
+		Title:      Title{Display: title, Gloss: ""},
+		TitleImage: "",
+		Vocab:      []VocabItem{},
+		Questions:  []Question{},
+		Content:    []ContentItem{},
+	}
+}
@@ -0,0 +1,232 @@
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// CatalogName is the catalog of the library, written at its root.
+const CatalogName = "catalog.json"
+
+// Catalog lists every book in the library, so that the app can find them
+// without knowing their slugs and paths in advance. Books are grouped by
+// collection, in library order.
+type Catalog struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	Collections   []CatalogCollection `json:"collections"`
+}
+
+// CatalogCollection is one shelf of the catalog, such as Plato or the
+// Apostolic Fathers. The shelves of a Collection carry its metadata, the
+// slugs of the collections within it and its omnibus; the other shelves
+// are only named, after the collection their books' manifests give.
+// Parent is the slug of the collection a collection is within.
+type CatalogCollection struct {
+	Name        string         `json:"name"`
+	Slug        string         `json:"slug,omitempty"`
+	GreekTitle  string         `json:"greekTitle,omitempty"`
+	Description string         `json:"description,omitempty"`
+	CoverImage  string         `json:"coverImage,omitempty"`
+	Parent      string         `json:"parent,omitempty"`
+	Collections []string       `json:"collections,omitempty"`
+	Books       []CatalogEntry `json:"books"`
+	Omnibus     *CatalogEntry  `json:"omnibus,omitempty"`
+}
+
+// CatalogEntry describes one book. Path, Index and CoverImage are relative
+// to the catalog, unless the cover is a URL; Index is set only for a book
+// that is also written as shards. Lemmas counts the distinct words of
+// the book by their folded form, which stands in for a count of lemmas
+// until the books are lemmatized, and Level grades the book from 1 to 5 by
+// that count.
+type CatalogEntry struct {
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Language    string `json:"language"`
+	Description string `json:"description"`
+	CoverImage  string `json:"coverImage,omitempty"`
+	Path        string `json:"path"`
+	Index       string `json:"index,omitempty"`
+	Restricted  bool   `json:"restricted,omitempty"`
+	Chapters    int    `json:"chapters"`
+	Words       int    `json:"words"`
+	Lemmas      int    `json:"lemmas"`
+	Level       int    `json:"level"`
+}
+
+// levelLemmas holds the most distinct words a book of each level up to 4
+// may have; books with more are level 5.
+var levelLemmas = []int{1000, 2000, 4000, 8000}
+
+// NewCatalog makes the catalog of the books named by manifests, reading
+// each from its output file. Books in the directory of one of collections
+// are shelved in the innermost such collection, in its order, and the rest
+// by the collection their manifest names. Paths in the catalog are made
+// relative to root, the directory the catalog is written in. A book whose
+// output cannot be read is left out, and the error for it returned
+// alongside the catalog.
+func NewCatalog(root string, manifests []*Manifest, collections []*Collection) (*Catalog, []error) {
+	c := &Catalog{SchemaVersion: SchemaVersion, Collections: []CatalogCollection{}}
+	errs := []error{}
+	index := map[string]int{}
+	// shelves maps the shelves of collections to them, and dirs the slugs
+	// of collections to their directories.
+	shelves := map[int]*Collection{}
+	dirs := map[string]string{}
+	// shelf returns the index of the shelf of a collection, or of the
+	// shelf named name when coll is nil, adding it and the shelves of the
+	// collections it is within as needed.
+	var shelf func(coll *Collection, name string) int
+	shelf = func(coll *Collection, name string) int {
+		key := "name:" + name
+		if coll != nil {
+			key = "dir:" + coll.Dir
+		}
+		if i, ok := index[key]; ok {
+			return i
+		}
+		if coll == nil {
+			index[key] = len(c.Collections)
+			c.Collections = append(c.Collections, CatalogCollection{Name: name, Books: []CatalogEntry{}})
+			return index[key]
+		}
+		cc := CatalogCollection{Name: coll.Name, Slug: coll.Slug, GreekTitle: coll.GreekTitle, Description: coll.Description, Books: []CatalogEntry{}}
+		if coll.Cover != "" {
+			cc.CoverImage = coll.Cover
+			if !strings.Contains(coll.Cover, "://") {
+				if rel, err := filepath.Rel(root, filepath.Join(coll.Dir, coll.Cover)); err == nil {
+					cc.CoverImage = filepath.ToSlash(rel)
+				}
+			}
+		}
+		parent := innermost(collections, filepath.Dir(coll.Dir))
+		if parent != nil {
+			p := shelf(parent, "")
+			cc.Parent = parent.Slug
+			c.Collections[p].Collections = append(c.Collections[p].Collections, coll.Slug)
+		}
+		index[key] = len(c.Collections)
+		shelves[index[key]] = coll
+		dirs[coll.Slug] = coll.Dir
+		c.Collections = append(c.Collections, cc)
+		return index[key]
+	}
+
+	// Books are taken shelf by shelf, in each collection's order.
+	ordered := []*Manifest{}
+	done := map[*Manifest]bool{}
+	for _, m := range manifests {
+		if done[m] {
+			continue
+		}
+		if coll := innermost(collections, m.Dir); coll != nil {
+			for _, b := range coll.Books(manifests) {
+				if !done[b] && innermost(collections, b.Dir) == coll {
+					done[b] = true
+					ordered = append(ordered, b)
+				}
+			}
+		}
+		if !done[m] {
+			done[m] = true
+			ordered = append(ordered, m)
+		}
+	}
+	for _, m := range ordered {
+		b, err := ReadFile(m.OutputPath())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rel, err := filepath.Rel(root, m.OutputPath())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		entry := catalogEntry(b, filepath.ToSlash(rel))
+		if _, err := os.Stat(filepath.Join(ShardDir(m.OutputPath()), IndexName)); err == nil {
+			entry.Index = path.Join(ShardDir(entry.Path), IndexName)
+		}
+		if m.Level != 0 {
+			entry.Level = m.Level
+		}
+		i := shelf(innermost(collections, m.Dir), m.Shelf(collections))
+		if m.omnibus != nil {
+			c.Collections[i].Omnibus = &entry
+			continue
+		}
+		c.Collections[i].Books = append(c.Collections[i].Books, entry)
+	}
+	// The collections within a collection follow its order too.
+	for i, coll := range shelves {
+		subs := c.Collections[i].Collections
+		sort.SliceStable(subs, func(a, b int) bool {
+			return coll.rank(dirs[subs[a]]) < coll.rank(dirs[subs[b]])
+		})
+	}
+	return c, errs
+}
+
+// catalogEntry describes a book whose file is at rel.
+func catalogEntry(b *Book, rel string) CatalogEntry {
+	entry := CatalogEntry{
+		Slug:        b.Slug,
+		Title:       b.Title,
+		Author:      b.Author,
+		Language:    b.Language,
+		Description: b.Description,
+		CoverImage:  b.CoverImage,
+		Path:        rel,
+		Restricted:  b.Restricted,
+		Chapters:    len(b.Chapters),
+	}
+	if entry.CoverImage != "" && !strings.Contains(entry.CoverImage, "://") {
+		entry.CoverImage = path.Join(path.Dir(rel), entry.CoverImage)
+	}
+	lemmas := map[string]bool{}
+	for _, chapter := range b.Chapters {
+		for _, item := range chapter.Content {
+			for _, p := range item.Paragraph {
+				if p.Ref == nil && p.VerseID == 0 {
+					continue
+				}
+				for _, w := range p.Words {
+					if w.Word == "" {
+						continue
+					}
+					entry.Words++
+					lemmas[greek.Fold(w.Word)] = true
+				}
+			}
+		}
+	}
+	entry.Lemmas = len(lemmas)
+	entry.Level = len(levelLemmas) + 1
+	for i, max := range levelLemmas {
+		if entry.Lemmas <= max {
+			entry.Level = i + 1
+			break
+		}
+	}
+	return entry
+}
+
+// WriteCatalog writes the catalog to path as indented JSON.
+func WriteCatalog(path string, c *Catalog) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing catalog: %v", err)
+	}
+	return nil
+}
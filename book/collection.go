@@ -0,0 +1,213 @@
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CollectionName is the file that marks a directory as holding a
+// collection of books.
+const CollectionName = "collection.json"
+
+// Collection is a shelf of related books, such as the Apostolic Fathers or
+// the dialogues of Plato: every book below the directory of its
+// collection.json. A collection may hold other collections, as the
+// Apostolic Fathers hold the letters of Ignatius; a book is shelved in the
+// innermost collection that holds it.
+type Collection struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	GreekTitle  string `json:"greekTitle,omitempty"`
+	Description string `json:"description,omitempty"`
+	Cover       string `json:"cover,omitempty"`
+	// Members lists the directories of the collection's books and of the
+	// collections in it, relative to its own, in the order they are
+	// shelved. Those it does not list follow in library order.
+	Members []string `json:"members,omitempty"`
+	// Omnibus, if set, is built as one more book holding every book of
+	// the collection in order, each as a part.
+	Omnibus *Omnibus `json:"omnibus,omitempty"`
+
+	Dir string `json:"-"`
+}
+
+// Omnibus describes the single volume a collection can be built as, such
+// as "The Seven Letters of Ignatius". Its slug and output default to the
+// collection's slug and <slug>.json in the collection's directory.
+type Omnibus struct {
+	Title       string `json:"title"`
+	GreekTitle  string `json:"greekTitle,omitempty"`
+	Slug        string `json:"slug,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Description string `json:"description,omitempty"`
+	Cover       string `json:"cover,omitempty"`
+	Output      string `json:"output,omitempty"`
+}
+
+// LoadCollection reads the collection in dir and fills in its defaults.
+func LoadCollection(dir string) (*Collection, error) {
+	path := filepath.Join(dir, CollectionName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading collection: %v", err)
+	}
+	c := &Collection{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+	c.Dir = dir
+	if c.Slug == "" {
+		c.Slug = filepath.Base(dir)
+	}
+	if c.Name == "" {
+		c.Name = c.Slug
+	}
+	if _, err := os.Stat(filepath.Join(dir, ManifestName)); err == nil && c.Omnibus != nil {
+		return nil, fmt.Errorf("%s: a collection with an omnibus cannot also hold a %s", dir, ManifestName)
+	}
+	return c, nil
+}
+
+// FindCollections walks root and loads every collection below it, sorted
+// by directory, so that a collection comes before those within it.
+func FindCollections(root string) ([]*Collection, error) {
+	dirs := []string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != root && d.Name()[0] == '.' {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == CollectionName {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+	collections := []*Collection{}
+	for _, dir := range dirs {
+		c, err := LoadCollection(dir)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, nil
+}
+
+// holds reports whether dir is the collection's directory or below it.
+func (c *Collection) holds(dir string) bool {
+	rel, err := filepath.Rel(c.Dir, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// rank returns the position among the collection's Members of the member
+// that holds dir, or len(Members) for one it does not list.
+func (c *Collection) rank(dir string) int {
+	rel, err := filepath.Rel(c.Dir, dir)
+	if err != nil {
+		return len(c.Members)
+	}
+	rel = filepath.ToSlash(rel)
+	for i, member := range c.Members {
+		member = strings.Trim(filepath.ToSlash(filepath.Clean(member)), "/")
+		if rel == member || strings.HasPrefix(rel, member+"/") {
+			return i
+		}
+	}
+	return len(c.Members)
+}
+
+// Books returns the manifests of the books the collection holds, including
+// those of the collections within it, in the collection's order. An
+// omnibus is not among them.
+func (c *Collection) Books(manifests []*Manifest) []*Manifest {
+	books := []*Manifest{}
+	for _, m := range manifests {
+		if m.omnibus == nil && c.holds(m.Dir) {
+			books = append(books, m)
+		}
+	}
+	sort.SliceStable(books, func(i, j int) bool {
+		return c.rank(books[i].Dir) < c.rank(books[j].Dir)
+	})
+	return books
+}
+
+// innermost returns the innermost of collections that holds dir, or nil.
+func innermost(collections []*Collection, dir string) *Collection {
+	var in *Collection
+	for _, c := range collections {
+		if c.holds(dir) && (in == nil || in.holds(c.Dir)) {
+			in = c
+		}
+	}
+	return in
+}
+
+// Shelf returns the name of the shelf the book is listed under: the
+// innermost of collections that holds it, or else the collection its
+// manifest names.
+func (m *Manifest) Shelf(collections []*Collection) string {
+	if c := innermost(collections, m.Dir); c != nil {
+		return c.Name
+	}
+	return m.Collection
+}
+
+// OmnibusManifest returns the manifest of the collection's omnibus: a work
+// whose volumes are the collection's books, in order, so that each is a
+// part of it. It returns nil if the collection has no omnibus.
+func (c *Collection) OmnibusManifest(manifests []*Manifest) (*Manifest, error) {
+	o := c.Omnibus
+	if o == nil {
+		return nil, nil
+	}
+	m := &Manifest{
+		Title:       o.Title,
+		GreekTitle:  o.GreekTitle,
+		Slug:        o.Slug,
+		Author:      o.Author,
+		Language:    "Greek",
+		Description: o.Description,
+		Cover:       o.Cover,
+		Collection:  c.Name,
+		Output:      o.Output,
+		Dir:         c.Dir,
+		File:        filepath.Join(c.Dir, CollectionName),
+		PrefixRefs:  true,
+		omnibus:     c,
+	}
+	if m.Slug == "" {
+		m.Slug = c.Slug
+	}
+	if m.Output == "" {
+		m.Output = m.Slug + ".json"
+	}
+	books := c.Books(manifests)
+	if len(books) == 0 {
+		return nil, fmt.Errorf("%s: omnibus %s has no books", c.Dir, m.Slug)
+	}
+	for _, b := range books {
+		if m.Author == "" {
+			m.Author = b.Author
+		} else if m.Author != b.Author && o.Author == "" {
+			m.Author = c.Name
+		}
+		rel, err := filepath.Rel(c.Dir, b.Dir)
+		if err != nil {
+			return nil, err
+		}
+		m.Volumes = append(m.Volumes, ManifestVolume{Dir: rel})
+	}
+	return m, nil
+}
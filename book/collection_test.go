@@ -0,0 +1,191 @@
+package book
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeLibrary makes a library of the Apostolic Fathers, holding a
+// collection of letters of Ignatius with an omnibus, and a book of Plato
+// that names its collection in its manifest. It returns the library's root.
+func writeLibrary(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"Fathers/" + CollectionName:                  `{"name": "Apostolic Fathers", "slug": "fathers", "members": ["didache", "ignatius/"]}`,
+		"Fathers/clement/" + ManifestName:            `{"title": "1 Clement", "author": "Clement"}`,
+		"Fathers/didache/" + ManifestName:            `{"title": "Didache", "author": "Anonymous"}`,
+		"Fathers/ignatius/" + CollectionName:         `{"members": ["romans"], "omnibus": {"title": "The Letters of Ignatius"}}`,
+		"Fathers/ignatius/ephesians/" + ManifestName: `{"title": "Ephesians", "author": "Ignatius"}`,
+		"Fathers/ignatius/romans/" + ManifestName:    `{"title": "Romans", "author": "Ignatius"}`,
+		"Plato/apology/" + ManifestName:              `{"title": "Apology", "author": "Plato", "collection": "Plato"}`,
+		".trash/" + CollectionName:                   `{"name": "Trash"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// titles returns the titles of the books of manifests.
+func titles(manifests []*Manifest) []string {
+	list := []string{}
+	for _, m := range manifests {
+		list = append(list, m.Title)
+	}
+	return list
+}
+
+func TestFindCollections(t *testing.T) {
+	root := writeLibrary(t)
+	collections, err := FindCollections(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collections) != 2 {
+		t.Fatalf("found %d collections, want 2", len(collections))
+	}
+	fathers, ignatius := collections[0], collections[1]
+	if fathers.Name != "Apostolic Fathers" || fathers.Slug != "fathers" {
+		t.Errorf("first collection %q (%s)", fathers.Name, fathers.Slug)
+	}
+	// The slug and name default to the directory's name.
+	if ignatius.Name != "ignatius" || ignatius.Slug != "ignatius" || ignatius.Dir != filepath.Join(root, "Fathers", "ignatius") {
+		t.Errorf("second collection %q (%s) in %s", ignatius.Name, ignatius.Slug, ignatius.Dir)
+	}
+
+	manifests, err := FindManifests(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Listed members come first in the collection's order, and the rest
+	// after them in library order; the books of a collection within are
+	// ordered by their own place in it only among themselves.
+	if got, want := titles(fathers.Books(manifests)), []string{"Didache", "Ephesians", "Romans", "1 Clement"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Apostolic Fathers books = %q, want %q", got, want)
+	}
+	if got, want := titles(ignatius.Books(manifests)), []string{"Romans", "Ephesians"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Ignatius books = %q, want %q", got, want)
+	}
+
+	shelves := map[string]string{}
+	for _, m := range manifests {
+		shelves[m.Title] = m.Shelf(collections)
+	}
+	want := map[string]string{
+		"1 Clement":               "Apostolic Fathers",
+		"Didache":                 "Apostolic Fathers",
+		"Ephesians":               "ignatius",
+		"Romans":                  "ignatius",
+		"The Letters of Ignatius": "ignatius",
+		"Apology":                 "Plato",
+	}
+	if !reflect.DeepEqual(shelves, want) {
+		t.Errorf("shelves = %v, want %v", shelves, want)
+	}
+}
+
+func TestOmnibusManifest(t *testing.T) {
+	root := writeLibrary(t)
+	manifests, err := FindManifests(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collections, err := FindCollections(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fathers, ignatius := collections[0], collections[1]
+	if m, err := fathers.OmnibusManifest(manifests); m != nil || err != nil {
+		t.Errorf("OmnibusManifest of a collection without one = %+v, %v", m, err)
+	}
+	m, err := ignatius.OmnibusManifest(manifests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Slug != "ignatius" || m.Output != "ignatius.json" || m.Author != "Ignatius" || !m.PrefixRefs || m.Collection != "ignatius" {
+		t.Errorf("omnibus manifest %+v", m)
+	}
+	if want := []ManifestVolume{{Dir: "romans"}, {Dir: "ephesians"}}; !reflect.DeepEqual(m.Volumes, want) {
+		t.Errorf("volumes = %+v, want %+v", m.Volumes, want)
+	}
+	// The omnibus itself is not one of the collection's books.
+	if got := titles(ignatius.Books(append(manifests, m))); len(got) != 2 {
+		t.Errorf("books with the omnibus = %q", got)
+	}
+
+	// Books by several authors are attributed to the collection unless the
+	// omnibus names an author.
+	for _, m := range manifests {
+		if m.Title == "Romans" {
+			m.Author = "Polycarp"
+		}
+	}
+	if m, _ := ignatius.OmnibusManifest(manifests); m.Author != "ignatius" {
+		t.Errorf("author of several = %q, want the collection's name", m.Author)
+	}
+	ignatius.Omnibus.Author = "Ignatius"
+	if m, _ := ignatius.OmnibusManifest(manifests); m.Author != "Ignatius" {
+		t.Errorf("author = %q, want the omnibus's own", m.Author)
+	}
+	if _, err := ignatius.OmnibusManifest(nil); err == nil || !strings.Contains(err.Error(), "has no books") {
+		t.Errorf("OmnibusManifest with no books = %v", err)
+	}
+}
+
+func TestLoadCollectionRefusesOmnibusBook(t *testing.T) {
+	root := writeLibrary(t)
+	dir := filepath.Join(root, "Fathers", "ignatius")
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), []byte(`{"title": "Letters"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCollection(dir); err == nil {
+		t.Error("LoadCollection accepted an omnibus in a book's directory")
+	}
+}
+
+func TestCollectionHolds(t *testing.T) {
+	c := &Collection{Dir: filepath.FromSlash("lib/Fathers"), Members: []string{"didache", "./ignatius/", "ignatius/romans"}}
+	tests := []struct {
+		dir   string
+		holds bool
+		rank  int
+	}{
+		{"lib/Fathers", true, 3},
+		{"lib/Fathers/didache", true, 0},
+		{"lib/Fathers/ignatius/romans", true, 1},
+		{"lib/Fathers/ignatius", true, 1},
+		{"lib/Fathers/ignatiusx", true, 3},
+		{"lib/Fathers/clement", true, 3},
+		{"lib/Fathers2", false, 3},
+		{"lib/..Fathers", false, 3},
+		{"lib", false, 3},
+	}
+	for _, tt := range tests {
+		dir := filepath.FromSlash(tt.dir)
+		if got := c.holds(dir); got != tt.holds {
+			t.Errorf("holds(%s) = %t, want %t", tt.dir, got, tt.holds)
+		}
+		if got := c.rank(dir); got != tt.rank {
+			t.Errorf("rank(%s) = %d, want %d", tt.dir, got, tt.rank)
+		}
+	}
+	outer, inner := &Collection{Dir: "lib"}, c
+	for _, collections := range [][]*Collection{{outer, inner}, {inner, outer}} {
+		if got := innermost(collections, filepath.FromSlash("lib/Fathers/didache")); got != inner {
+			t.Errorf("innermost = %+v, want the inner collection", got)
+		}
+	}
+	if got := innermost([]*Collection{inner}, "Plato"); got != nil {
+		t.Errorf("innermost of a book in no collection = %+v", got)
+	}
+}
@@ -0,0 +1,56 @@
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ConfigName is the file at the root of the library that says how its
+// books are published.
+const ConfigName = "grbook.config.json"
+
+// Config says how the books of a library are published, so that build,
+// check and the golden test write the same books.
+type Config struct {
+	// CoverURL, if set, is the URL the root of the library is published
+	// at, such as a raw.githubusercontent.com link to the repository.
+	// Covers are then given as absolute URLs under it; see CoverOptions.
+	CoverURL string `json:"coverURL,omitempty"`
+	// Thumbs are the widths of the thumbnails made of each cover,
+	// DefaultThumbWidths if not given.
+	Thumbs []int `json:"thumbs,omitempty"`
+}
+
+// LoadConfig reads the config of the library at root. A library with no
+// config has the default one.
+func LoadConfig(root string) (*Config, error) {
+	path := filepath.Join(root, ConfigName)
+	c := &Config{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = []byte("{}"), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %v", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+	if c.Thumbs == nil {
+		c.Thumbs = DefaultThumbWidths
+	}
+	for _, w := range c.Thumbs {
+		if w < 1 {
+			return nil, fmt.Errorf("%s: invalid thumbnail width %d", path, w)
+		}
+	}
+	return c, nil
+}
+
+// CoverOptions returns the options AddCover publishes the covers of the
+// library at root with, shelving books in collections.
+func (c *Config) CoverOptions(root string, collections []*Collection) CoverOptions {
+	return CoverOptions{BaseURL: c.CoverURL, Root: root, Collections: collections, Widths: c.Thumbs}
+}
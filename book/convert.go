@@ -0,0 +1,318 @@
+package book
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// ConverterVersion goes up whenever a change to Convert alters the books it
+// builds from unchanged sources, so that cached builds are redone.
+const ConverterVersion = 6
+
+// Convert reads the source text named by the manifest, normalizes it and
+// parses it with the manifest's reference scheme, detecting the scheme when
+// none is given. Sources in the sectioned format are read by parseSections.
+// Besides the book it returns warnings about lines that look mistaken. A
+// work of several volumes is converted by convertVolumes.
+func Convert(m *Manifest) (*Book, []Diagnostic, error) {
+	if len(m.Volumes) > 0 {
+		return convertVolumes(m)
+	}
+	content, err := os.ReadFile(m.SourcePath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading file: %v", err)
+	}
+	lines := strings.Split(greek.Normalize(string(content)), "\n")
+	diags := &diagnostics{path: m.SourcePath()}
+
+	switch m.Format {
+	case "":
+	case "sections":
+		book := m.NewBook()
+		if err := parseSections(book, lines, diags); err != nil {
+			return nil, nil, err
+		}
+		numberVerses(book)
+		return book, diags.list, nil
+	default:
+		return nil, nil, fmt.Errorf("%s: unknown format %q", m.Dir, m.Format)
+	}
+
+	var scheme RefScheme
+	if m.Scheme == "" {
+		scheme = DetectScheme(lines)
+	} else if scheme, err = LookupScheme(m.Scheme); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", m.Dir, err)
+	}
+
+	book := m.NewBook()
+	g := &grouper{
+		book:       book,
+		grouping:   m.Grouping,
+		titling:    Grouping{Title: m.ChapterTitle, Numerals: m.Numerals},
+		chapterMap: make(map[int]*Chapter),
+		titles:     make(map[int]Title),
+		dialogue:   m.Dialogue,
+		speakers:   m.Speakers,
+		diags:      diags,
+	}
+	// Groupings other than those of the source's own chapters are made
+	// once the whole book has been read into one chapter.
+	if err := checkNumerals(m.Numerals); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", m.Dir, err)
+	}
+	var split *Grouping
+	switch m.Grouping {
+	case "top", "titles":
+	default:
+		if m.Grouping != "" {
+			split = &Grouping{By: m.Grouping, Size: m.GroupSize, Title: m.ChapterTitle, Numerals: m.Numerals}
+			if err := split.check(); err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", m.Dir, err)
+			}
+		}
+		g.chapter = NewChapter("chapter-1", book.Title)
+		book.Chapters = append(book.Chapters, g.chapter)
+	}
+	sections := m.Sections
+	if sections != nil {
+		if sections.Numerals == "" {
+			s := *sections
+			s.Numerals = m.Numerals
+			sections = &s
+		}
+		if err := sections.check(); err != nil {
+			return nil, nil, fmt.Errorf("%s: sections: %v", m.Dir, err)
+		}
+	}
+	var last Ref
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		l, ok := scheme.Parse(line)
+		if !ok {
+			diags.warn(i+1, "line not recognised by the %s scheme", scheme.Name())
+			continue
+		}
+		if l.Kind == VerseLine && !l.Ref.IsZero() {
+			if !last.IsZero() && l.Ref.Compare(last) <= 0 {
+				diags.warn(i+1, "verse %s comes after %s", l.Ref, last)
+			}
+			last = l.Ref
+		}
+		if err := g.add(l, i+1); err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: %v", m.SourcePath(), i+1, err)
+		}
+	}
+	if err := g.markup.finish(); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", m.SourcePath(), err)
+	}
+	if split != nil {
+		splitChapters(book, *split)
+	}
+	if len(m.Parts) > 0 {
+		if err := applyManifestParts(book, m.Parts); err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", m.Dir, err)
+		}
+	}
+	book.Parts = pruneParts(book.Parts)
+	fillTitles(book)
+	numberChapters(book)
+	if sections != nil {
+		splitSections(book, *sections)
+	}
+	numberVerses(book)
+	indexStephanus(book)
+	return book, diags.list, nil
+}
+
+// grouper distributes parsed lines into the book's chapters.
+type grouper struct {
+	book     *Book
+	grouping string
+	titling  Grouping
+	chapter  *Chapter
+	markup   markupParser
+
+	// chapterMap and titles are keyed by top-level ref for "top" grouping,
+	// and titleRef is the ref of the last title line for "titles".
+	chapterMap map[int]*Chapter
+	titles     map[int]Title
+	titleRef   Ref
+
+	// speaker is the current speaker of a dialogue, and inTurn whether the
+	// last content item of the chapter holds the speaker's current turn.
+	// Speaker lines are only read in a dialogue.
+	dialogue bool
+	speaker  string
+	inTurn   bool
+	speakers map[string]Speaker
+	diags    *diagnostics
+
+	// part is the part new chapters go into: the heading most recently
+	// read, or the part above it. open is set while no chapter has
+	// started since the part's heading, so that a heading the source
+	// spreads over several lines is read as one.
+	part            *Part
+	top             *Part
+	open            bool
+	pendingSubtitle string
+}
+
+func (g *grouper) add(l Line, lineNo int) error {
+	switch l.Kind {
+	case PartLine:
+		if g.open && g.part == g.top {
+			joinTitle(&g.part.Title, l)
+			return nil
+		}
+		g.top = newPart(countParts(g.book.Parts)+1, "")
+		l.setTitle(&g.top.Title)
+		g.book.Parts = append(g.book.Parts, g.top)
+		g.part, g.open = g.top, true
+	case HeadingLine:
+		if g.open && g.part != g.top {
+			joinTitle(&g.part.Title, l)
+			return nil
+		}
+		g.part = newPart(countParts(g.book.Parts)+1, "")
+		l.setTitle(&g.part.Title)
+		if g.top != nil {
+			g.top.Parts = append(g.top.Parts, g.part)
+		} else {
+			g.book.Parts = append(g.book.Parts, g.part)
+		}
+		g.open = true
+	case TitleLine:
+		switch g.grouping {
+		case "top":
+			top := l.Ref.Levels[0]
+			title := g.titles[top]
+			l.setTitle(&title)
+			g.titles[top] = title
+			if chapter := g.chapterMap[top]; chapter != nil {
+				l.setTitle(&chapter.Title)
+			}
+		case "titles":
+			// The Greek and English titles of one chapter share a ref.
+			if g.chapter == nil || len(g.chapter.Content) > 0 || l.Ref.Compare(g.titleRef) != 0 {
+				g.startChapter(fmt.Sprintf("chapter-%d", len(g.book.Chapters)+1), "")
+				g.titleRef = l.Ref
+			}
+			l.setTitle(&g.chapter.Title)
+		default:
+			g.pendingSubtitle = l.Text
+		}
+	case VerseLine:
+		switch g.grouping {
+		case "top":
+			top := l.Ref.Levels[0]
+			if g.chapterMap[top] == nil {
+				title, ok := g.titles[top]
+				if !ok {
+					n := strconv.Itoa(top)
+					title.Display = g.titling.chapterTitle(top, group{first: n, last: n})
+				}
+				g.startChapter(fmt.Sprintf("chapter-%d", top), "")
+				g.chapter.Title = title
+				g.chapterMap[top] = g.chapter
+			}
+			if g.chapter != g.chapterMap[top] {
+				g.chapter = g.chapterMap[top]
+				g.inTurn = false
+			}
+		case "titles":
+			if g.chapter == nil {
+				g.startChapter("chapter-1", g.book.Title)
+			}
+		}
+		section := g.markup.section
+		words, err := g.markup.words(l.Text, lineNo)
+		if err != nil {
+			return err
+		}
+		if abbrev, ok := speakerLine(l.Ref, words); ok && g.dialogue {
+			// The speaker's name is not a verse; hand any Stephanus
+			// anchor on it on to the first word the speaker says.
+			if words[0].Stephanus != "" {
+				g.markup.stephanus = words[0].Stephanus
+			}
+			g.setSpeaker(abbrev, lineNo)
+			return nil
+		}
+		if len(words) > 0 && words[0].Stephanus != "" {
+			section = words[0].Stephanus
+		}
+		ref := l.Ref
+		g.appendParagraph(Paragraph{Ref: &ref, Stephanus: section, Speaker: g.speaker, Words: words})
+	}
+	return nil
+}
+
+// setSpeaker starts a new speaking turn, adding the speaker to the book's
+// dramatis personae when they first speak.
+func (g *grouper) setSpeaker(abbrev string, lineNo int) {
+	g.speaker = abbrev
+	g.inTurn = false
+	for _, s := range g.book.Speakers {
+		if s.Abbrev == abbrev {
+			return
+		}
+	}
+	s, ok := lookupSpeaker(abbrev, g.speakers)
+	if !ok {
+		g.diags.warn(lineNo, "unknown speaker %s", abbrev)
+	}
+	g.book.Speakers = append(g.book.Speakers, s)
+}
+
+func (g *grouper) startChapter(slug, title string) {
+	g.chapter = NewChapter(slug, title)
+	g.book.Chapters = append(g.book.Chapters, g.chapter)
+	g.inTurn = false
+	if g.part != nil {
+		g.part.Chapters = append(g.part.Chapters, slug)
+	}
+	g.open = false
+}
+
+// appendParagraph adds a verse to the chapter. Each verse is its own
+// content item, except that the verses of one speaking turn share one.
+func (g *grouper) appendParagraph(paragraph Paragraph) {
+	if g.inTurn && paragraph.Speaker != "" && g.pendingSubtitle == "" {
+		last := &g.chapter.Content[len(g.chapter.Content)-1]
+		last.Paragraph = append(last.Paragraph, paragraph)
+		return
+	}
+	g.chapter.Content = append(g.chapter.Content, ContentItem{
+		Subtitle:  g.pendingSubtitle,
+		Image:     "",
+		Speaker:   paragraph.Speaker,
+		Paragraph: []Paragraph{paragraph},
+	})
+	g.pendingSubtitle = ""
+	g.inTurn = paragraph.Speaker != ""
+}
+
+// numberVerses sets each verse's VerseID to its position in the book.
+// Paragraphs without a ref keep VerseID 0.
+func numberVerses(book *Book) {
+	n := 0
+	for _, chapter := range book.Chapters {
+		for i := range chapter.Content {
+			for j := range chapter.Content[i].Paragraph {
+				p := &chapter.Content[i].Paragraph[j]
+				if p.Ref != nil {
+					n++
+					p.VerseID = n
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package book
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// convertSource converts source with the manifest given as JSON, from a
+// book directory made for the test.
+func convertSource(t *testing.T, manifest, source string) (*Book, []Diagnostic) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "test")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, diags, err := Convert(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, diags
+}
+
+// messages returns the line and message of each diagnostic.
+func messages(diags []Diagnostic) []string {
+	list := []string{}
+	for _, d := range diags {
+		list = append(list, fmt.Sprintf("%s:%d: %s", filepath.Base(d.Path), d.Line, d.Message))
+	}
+	return list
+}
+
+func TestConvertWarnsOfRefsOutOfOrder(t *testing.T) {
+	_, diags := convertSource(t, `{"title": "Test"}`, "1.1 ἐν ἀρχῇ\n1.2 ἦν ὁ λόγος\n1.2 καὶ ὁ λόγος\n\n1.1 ἦν πρὸς τὸν θεόν\n2.1 οὗτος ἦν\n")
+	want := []string{
+		"test.txt:3: verse 1.2 comes after 1.2",
+		"test.txt:5: verse 1.1 comes after 1.2",
+	}
+	if got := messages(diags); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,277 @@
+package book
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Thumbnail is a smaller copy of a book's cover.
+type Thumbnail struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+}
+
+// CoverOptions says how AddCover publishes covers.
+type CoverOptions struct {
+	// BaseURL, if set, is the URL the library's Root directory is
+	// published at, such as a raw.githubusercontent.com link to the
+	// repository. Covers are then given as absolute URLs; otherwise they
+	// are given relative to the book file.
+	BaseURL string
+	Root    string
+	// Collections are the collections of the library, which give the
+	// shelf a placeholder cover names and takes its colours from.
+	Collections []*Collection
+	// Widths are the widths, in pixels, of the thumbnails made of each
+	// cover, in PNG and JPEG.
+	Widths []int
+	// NoWrite sets a book's cover and thumbnails as AddCover would
+	// without writing any image or removing an old placeholder, so that
+	// a rebuilt book can be compared with the one committed.
+	NoWrite bool
+}
+
+// DefaultThumbWidths are the widths of the thumbnails made of each cover
+// unless others are asked for.
+var DefaultThumbWidths = []int{200, 600}
+
+// coverExts are the image types a cover may be, in the order they are
+// looked for.
+var coverExts = []string{".png", ".jpg", ".jpeg"}
+
+// minCoverWidth is the narrowest a cover may be.
+const minCoverWidth = 300
+
+// CoverPath returns the path of the book's cover: the manifest's cover if
+// it names one, or else an image named after the slug in the book's
+// directory. It returns "" if the book has no cover.
+func (m *Manifest) CoverPath() string {
+	if m.Cover != "" {
+		if strings.Contains(m.Cover, "://") {
+			return ""
+		}
+		return filepath.Join(m.Dir, m.Cover)
+	}
+	for _, ext := range coverExts {
+		path := filepath.Join(m.Dir, m.Slug+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// AddCover checks the book's cover, writes its thumbnails beside it and
+// sets the book's CoverImage and Thumbnails. A book with no cover is
+// reported and given a placeholder cover, written beside the book file,
+// instead. A cover that cannot be read is an error.
+func AddCover(b *Book, m *Manifest, opts CoverOptions) ([]Diagnostic, error) {
+	diags := &diagnostics{path: m.Dir}
+	if strings.Contains(m.Cover, "://") {
+		return nil, nil
+	}
+	url := func(file string) (string, error) {
+		if opts.BaseURL == "" {
+			return relPath(filepath.Dir(m.OutputPath()), file)
+		}
+		rel, err := relPath(opts.Root, file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(opts.BaseURL, "/") + "/" + rel, nil
+	}
+	cover := m.CoverPath()
+	if cover == "" {
+		diags.warn(0, "no cover image; using a placeholder")
+		placeholder := m.PlaceholderPath()
+		if !opts.NoWrite {
+			if err := writePlaceholder(placeholder, m, m.Shelf(opts.Collections)); err != nil {
+				return nil, err
+			}
+		}
+		u, err := url(placeholder)
+		if err != nil {
+			return nil, err
+		}
+		b.CoverImage, b.Thumbnails = u, nil
+		return diags.list, nil
+	}
+	img, err := readCover(cover)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.NoWrite {
+		os.Remove(m.PlaceholderPath())
+	}
+	if b.CoverImage, err = url(cover); err != nil {
+		return nil, err
+	}
+
+	b.Thumbnails = nil
+	bounds := img.Bounds()
+	var src *image.NRGBA
+	for _, width := range opts.Widths {
+		if width >= bounds.Dx() {
+			diags.warn(0, "cover %s is only %dpx wide, so no %dpx thumbnail is made", filepath.Base(cover), bounds.Dx(), width)
+			continue
+		}
+		if src == nil && !opts.NoWrite {
+			src = image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+			draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+		}
+		height := bounds.Dy() * width / bounds.Dx()
+		var thumb *image.NRGBA
+		if !opts.NoWrite {
+			thumb = resize(src, width, height)
+		}
+		for _, format := range thumbFormats {
+			file := thumbPath(cover, width, format)
+			if thumb != nil {
+				if err := writeImage(file, thumb, format); err != nil {
+					return nil, err
+				}
+			}
+			u, err := url(file)
+			if err != nil {
+				return nil, err
+			}
+			b.Thumbnails = append(b.Thumbnails, Thumbnail{Width: width, Height: height, Format: format, URL: u})
+		}
+	}
+	return diags.list, nil
+}
+
+// thumbFormats are the formats each thumbnail is made in.
+var thumbFormats = []string{"png", "jpeg"}
+
+// thumbPath returns the path of the thumbnail of cover at the given width
+// and format, such as republic-200.jpg beside republic.png.
+func thumbPath(cover string, width int, format string) string {
+	ext := map[string]string{"png": ".png", "jpeg": ".jpg"}[format]
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(cover, filepath.Ext(cover)), width, ext)
+}
+
+// CoverFiles returns the files AddCover writes for the book when making
+// thumbnails of the given widths: the thumbnails its cover is wide enough
+// for, or its placeholder if it has no cover.
+func (m *Manifest) CoverFiles(widths []int) ([]string, error) {
+	if strings.Contains(m.Cover, "://") {
+		return nil, nil
+	}
+	cover := m.CoverPath()
+	if cover == "" {
+		return []string{m.PlaceholderPath()}, nil
+	}
+	f, err := os.Open(cover)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cover: %v", err)
+	}
+	defer f.Close()
+	config, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid cover image: %v", cover, err)
+	}
+	files := []string{}
+	for _, width := range widths {
+		if width >= config.Width {
+			continue
+		}
+		for _, format := range thumbFormats {
+			files = append(files, thumbPath(cover, width, format))
+		}
+	}
+	return files, nil
+}
+
+// readCover decodes a cover and checks that it is a PNG or JPEG image wide
+// enough to show.
+func readCover(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cover: %v", err)
+	}
+	defer f.Close()
+	img, format, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid cover image: %v", path, err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); (format == "png") != (ext == ".png") {
+		return nil, fmt.Errorf("%s: cover is a %s image", path, format)
+	}
+	if w := img.Bounds().Dx(); w < minCoverWidth {
+		return nil, fmt.Errorf("%s: cover is %dpx wide, less than the %dpx required", path, w, minCoverWidth)
+	}
+	return img, nil
+}
+
+func writeImage(path string, img image.Image, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error writing thumbnail: %v", err)
+	}
+	if format == "png" {
+		err = png.Encode(f, img)
+	} else {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 85})
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing thumbnail: %v", err)
+	}
+	return nil
+}
+
+// resize scales src down to width by height, averaging the source pixels
+// that fall in each pixel of the result.
+func resize(src *image.NRGBA, width, height int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, max((y+1)*sh/height, y*sh/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, max((x+1)*sw/width, x*sw/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(row[sx*4+c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := y*dst.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// relPath returns path relative to root, with forward slashes.
+func relPath(root, file string) (string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absRoot, absFile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
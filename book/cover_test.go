@@ -0,0 +1,107 @@
+package book
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// coverManifest makes a book directory holding a blank PNG cover of the
+// given size, or no cover if width is 0, and returns its manifest.
+func coverManifest(t *testing.T, root string, width, height int) *Manifest {
+	t.Helper()
+	dir := filepath.Join(root, "shelf", "test")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if width > 0 {
+		f, err := os.Create(filepath.Join(dir, "test.png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := png.Encode(f, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Manifest{Title: "Test", Slug: "test", Output: "test.json", Dir: dir}
+}
+
+func TestAddCover(t *testing.T) {
+	root := t.TempDir()
+	m := coverManifest(t, root, 800, 1200)
+	b := m.NewBook()
+	if _, err := AddCover(b, m, CoverOptions{Root: root, Widths: []int{200, 1000}, NoWrite: true}); err != nil {
+		t.Fatal(err)
+	}
+	if b.CoverImage != "test.png" {
+		t.Errorf("CoverImage = %q, want test.png", b.CoverImage)
+	}
+	want := []Thumbnail{
+		{Width: 200, Height: 300, Format: "png", URL: "test-200.png"},
+		{Width: 200, Height: 300, Format: "jpeg", URL: "test-200.jpg"},
+	}
+	if !reflect.DeepEqual(b.Thumbnails, want) {
+		t.Errorf("Thumbnails = %+v, want %+v", b.Thumbnails, want)
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir, "test-200.png")); !os.IsNotExist(err) {
+		t.Errorf("NoWrite wrote a thumbnail")
+	}
+
+	if _, err := AddCover(b, m, CoverOptions{BaseURL: "https://example.com/library/", Root: root, Widths: []int{200}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://example.com/library/shelf/test/test.png"; b.CoverImage != want {
+		t.Errorf("CoverImage = %q, want %q", b.CoverImage, want)
+	}
+	if want := "https://example.com/library/shelf/test/test-200.jpg"; len(b.Thumbnails) != 2 || b.Thumbnails[1].URL != want {
+		t.Errorf("Thumbnails = %+v, want the JPEG at %s", b.Thumbnails, want)
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir, "test-200.jpg")); err != nil {
+		t.Errorf("no thumbnail written: %v", err)
+	}
+}
+
+func TestAddCoverPlaceholder(t *testing.T) {
+	root := t.TempDir()
+	m := coverManifest(t, root, 0, 0)
+	b := m.NewBook()
+	diags, err := AddCover(b, m, CoverOptions{Root: root, Widths: DefaultThumbWidths})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 {
+		t.Errorf("got %d diagnostics, want the missing cover reported", len(diags))
+	}
+	if b.CoverImage != "test.svg" || b.Thumbnails != nil {
+		t.Errorf("CoverImage = %q, Thumbnails = %v, want the placeholder alone", b.CoverImage, b.Thumbnails)
+	}
+	if _, err := os.Stat(m.PlaceholderPath()); err != nil {
+		t.Errorf("no placeholder written: %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	c, err := LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.CoverURL != "" || !reflect.DeepEqual(c.Thumbs, DefaultThumbWidths) {
+		t.Errorf("default config = %+v", c)
+	}
+	os.WriteFile(filepath.Join(root, ConfigName), []byte(`{"coverURL": "https://example.com", "thumbs": [100]}`), 0o644)
+	if c, err = LoadConfig(root); err != nil {
+		t.Fatal(err)
+	}
+	if opts := c.CoverOptions(root, nil); opts.BaseURL != "https://example.com" || !reflect.DeepEqual(opts.Widths, []int{100}) {
+		t.Errorf("CoverOptions = %+v", opts)
+	}
+	os.WriteFile(filepath.Join(root, ConfigName), []byte(`{"thumbs": [0]}`), 0o644)
+	if _, err := LoadConfig(root); err == nil {
+		t.Errorf("LoadConfig accepted a thumbnail width of 0")
+	}
+}
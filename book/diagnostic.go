@@ -0,0 +1,31 @@
+package book
+
+import (
+	"fmt"
+)
+
+// A Diagnostic is a warning about a line of a source text that Convert
+// could read past but that is probably a mistake, or with Line 0 about a
+// book as a whole.
+type Diagnostic struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s", d.Path, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", d.Path, d.Line, d.Message)
+}
+
+// diagnostics collects the warnings raised while converting one source.
+type diagnostics struct {
+	path string
+	list []Diagnostic
+}
+
+func (d *diagnostics) warn(line int, format string, args ...any) {
+	d.list = append(d.list, Diagnostic{Path: d.path, Line: line, Message: fmt.Sprintf(format, args...)})
+}
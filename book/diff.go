@@ -0,0 +1,192 @@
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Diff compares two versions of a book and describes how new differs from
+// old, chapter by chapter and verse by verse. Verses are matched by ref, or
+// by position within their chapter when either version lacks refs.
+func Diff(old, new *Book) []string {
+	diffs := []string{}
+	field := func(name string, a, b any) {
+		if !reflect.DeepEqual(a, b) {
+			diffs = append(diffs, fmt.Sprintf("%s: %v -> %v", name, show(a), show(b)))
+		}
+	}
+	field("schemaVersion", old.SchemaVersion, new.SchemaVersion)
+	field("title", old.Title, new.Title)
+	field("slug", old.Slug, new.Slug)
+	field("author", old.Author, new.Author)
+	field("language", old.Language, new.Language)
+	field("description", old.Description, new.Description)
+	field("coverImage", old.CoverImage, new.CoverImage)
+	field("thumbnails", old.Thumbnails, new.Thumbnails)
+	field("restricted", old.Restricted, new.Restricted)
+	field("speakers", old.Speakers, new.Speakers)
+	field("parts", old.Parts, new.Parts)
+	field("vocab", old.Vocab, new.Vocab)
+	if len(old.Stephanus) != len(new.Stephanus) {
+		diffs = append(diffs, fmt.Sprintf("stephanus: %d anchors -> %d", len(old.Stephanus), len(new.Stephanus)))
+	} else {
+		field("stephanus", old.Stephanus, new.Stephanus)
+	}
+
+	oldChapters := map[string]*Chapter{}
+	for _, c := range old.Chapters {
+		oldChapters[c.Slug] = c
+	}
+	newChapters := map[string]bool{}
+	for _, c := range new.Chapters {
+		newChapters[c.Slug] = true
+		if o := oldChapters[c.Slug]; o == nil {
+			diffs = append(diffs, fmt.Sprintf("chapter %s added (%d verses)", c.Slug, len(chapterVerses(c))))
+		} else {
+			diffs = append(diffs, diffChapter(o, c)...)
+		}
+	}
+	for _, c := range old.Chapters {
+		if !newChapters[c.Slug] {
+			diffs = append(diffs, fmt.Sprintf("chapter %s removed (%d verses)", c.Slug, len(chapterVerses(c))))
+		}
+	}
+	return diffs
+}
+
+func diffChapter(old, new *Chapter) []string {
+	diffs := []string{}
+	field := func(name string, a, b any) {
+		if !reflect.DeepEqual(a, b) {
+			diffs = append(diffs, fmt.Sprintf("chapter %s %s: %v -> %v", new.Slug, name, show(a), show(b)))
+		}
+	}
+	field("title", old.Title, new.Title)
+	field("number", old.Number, new.Number)
+	field("titleImage", old.TitleImage, new.TitleImage)
+	field("vocab", old.Vocab, new.Vocab)
+	field("questions", old.Questions, new.Questions)
+	field("subtitles", subtitles(old), subtitles(new))
+
+	oldVerses, newVerses := chapterVerses(old), chapterVerses(new)
+	if !hasRefs(oldVerses) || !hasRefs(newVerses) {
+		for i := 0; i < len(oldVerses) || i < len(newVerses); i++ {
+			where := fmt.Sprintf("chapter %s verse %d", new.Slug, i+1)
+			switch {
+			case i >= len(oldVerses):
+				diffs = append(diffs, fmt.Sprintf("%s added: %q", where, newVerses[i].Text()))
+			case i >= len(newVerses):
+				diffs = append(diffs, fmt.Sprintf("%s removed: %q", where, oldVerses[i].Text()))
+			default:
+				diffs = append(diffs, diffVerse(where, oldVerses[i], newVerses[i])...)
+			}
+		}
+		return diffs
+	}
+	// Paragraphs without a ref, such as part markers, are not matched. A
+	// ref that a source repeats is matched occurrence by occurrence.
+	oldKeys, newKeys := refKeys(oldVerses), refKeys(newVerses)
+	byKey := map[string]Paragraph{}
+	for i, p := range oldVerses {
+		if p.Ref != nil {
+			byKey[oldKeys[i]] = p
+		}
+	}
+	seen := map[string]bool{}
+	for i, p := range newVerses {
+		if p.Ref == nil {
+			continue
+		}
+		where := fmt.Sprintf("chapter %s verse %s", new.Slug, p.Ref)
+		seen[newKeys[i]] = true
+		if o, ok := byKey[newKeys[i]]; ok {
+			diffs = append(diffs, diffVerse(where, o, p)...)
+		} else {
+			diffs = append(diffs, fmt.Sprintf("%s added: %q", where, p.Text()))
+		}
+	}
+	for i, p := range oldVerses {
+		if p.Ref != nil && !seen[oldKeys[i]] {
+			diffs = append(diffs, fmt.Sprintf("chapter %s verse %s removed: %q", new.Slug, p.Ref, p.Text()))
+		}
+	}
+	return diffs
+}
+
+func diffVerse(where string, old, new Paragraph) []string {
+	if old.Text() != new.Text() {
+		return []string{fmt.Sprintf("%s text: %q -> %q", where, old.Text(), new.Text())}
+	}
+	diffs := []string{}
+	if old.VerseID != new.VerseID {
+		diffs = append(diffs, fmt.Sprintf("%s verse_id: %d -> %d", where, old.VerseID, new.VerseID))
+	}
+	if old.Speaker != new.Speaker {
+		diffs = append(diffs, fmt.Sprintf("%s speaker: %q -> %q", where, old.Speaker, new.Speaker))
+	}
+	if old.Stephanus != new.Stephanus {
+		diffs = append(diffs, fmt.Sprintf("%s stephanus: %q -> %q", where, old.Stephanus, new.Stephanus))
+	}
+	for i := 0; i < len(new.Words) && i < len(old.Words); i++ {
+		if !reflect.DeepEqual(old.Words[i], new.Words[i]) {
+			diffs = append(diffs, fmt.Sprintf("%s word %d: %s -> %s", where, i+1, show(old.Words[i]), show(new.Words[i])))
+			break
+		}
+	}
+	return diffs
+}
+
+// chapterVerses lists the verses of a chapter in reading order.
+func chapterVerses(chapter *Chapter) []Paragraph {
+	verses := []Paragraph{}
+	for _, item := range chapter.Content {
+		verses = append(verses, item.Paragraph...)
+	}
+	return verses
+}
+
+// refKeys keys each verse by its ref and how many times the ref has
+// appeared before it.
+func refKeys(verses []Paragraph) []string {
+	keys := make([]string, len(verses))
+	count := map[string]int{}
+	for i, p := range verses {
+		if p.Ref != nil {
+			keys[i] = fmt.Sprintf("%s#%d", p.Ref.Cite, count[p.Ref.Cite])
+			count[p.Ref.Cite]++
+		}
+	}
+	return keys
+}
+
+func hasRefs(verses []Paragraph) bool {
+	for _, p := range verses {
+		if p.Ref == nil && p.VerseID != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func subtitles(chapter *Chapter) []string {
+	s := []string{}
+	for _, item := range chapter.Content {
+		if item.Subtitle != "" {
+			s = append(s, item.Subtitle)
+		}
+	}
+	return s
+}
+
+// show formats a value for a difference report.
+func show(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
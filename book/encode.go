@@ -0,0 +1,385 @@
+package book
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"unicode"
+)
+
+// Encoding selects how WriteEncoded writes a book.
+type Encoding struct {
+	// Compact leaves out indentation and empty glosses, and writes each
+	// distinct gloss once, in a table at the top of the file that words
+	// refer to by index.
+	Compact bool
+	// Gzip writes a gzip-compressed copy of the file alongside it, named
+	// with a .gz suffix.
+	Gzip bool
+	// Shards also writes the book as a directory of chapter files; see
+	// WriteShards.
+	Shards bool
+}
+
+// WriteFile writes the book to path as indented JSON.
+func WriteFile(path string, book *Book) error {
+	return WriteEncoded(path, book, Encoding{})
+}
+
+// WriteEncoded writes the book to path in the given encoding. A gzip copy
+// or shards left beside path by an earlier build in another encoding are
+// removed, so that they are not taken for the book.
+func WriteEncoded(path string, book *Book, enc Encoding) error {
+	if !enc.Gzip {
+		if err := os.Remove(path + ".gz"); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing old output file: %v", err)
+		}
+	}
+	if dir := ShardDir(path); !enc.Shards {
+		if _, err := os.Stat(filepath.Join(dir, IndexName)); err == nil {
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("error removing old shards: %v", err)
+			}
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	defer f.Close()
+	buf := bufio.NewWriter(f)
+	var w io.Writer = buf
+	var zf *os.File
+	var zw *gzip.Writer
+	if enc.Gzip {
+		if zf, err = os.Create(path + ".gz"); err != nil {
+			return fmt.Errorf("error writing output file: %v", err)
+		}
+		defer zf.Close()
+		zw, _ = gzip.NewWriterLevel(zf, gzip.BestCompression)
+		w = io.MultiWriter(buf, zw)
+	}
+	if err := Encode(w, book, enc.Compact); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("error writing output file: %v", err)
+		}
+	}
+	if enc.Shards {
+		return WriteShards(ShardDir(path), book, enc)
+	}
+	return nil
+}
+
+// Encode writes the book to w as JSON, one chapter at a time so that the
+// whole file is never held in memory. The indented form is the same as
+// json.MarshalIndent would give, except that the levels of each ref are
+// written on one line; see joinLevels.
+func Encode(w io.Writer, book *Book, compact bool) error {
+	// The book is encoded without its chapters, which are then written
+	// where the empty "chapters" field was.
+	head := *book
+	head.Chapters = nil
+	var header, placeholder []byte
+	var err error
+	var glosses *glossTable
+	if compact {
+		glosses = newGlossTable(book)
+		header, err = json.Marshal(compactHeader{Encoding: "compact", Glosses: glosses.list, Book: &head})
+		placeholder = []byte(`"chapters":null`)
+	} else {
+		header, err = json.MarshalIndent(&head, "", "  ")
+		placeholder = []byte(`"chapters": null`)
+	}
+	if err != nil {
+		return err
+	}
+	before, after, ok := bytes.Cut(header, placeholder)
+	if !ok {
+		return fmt.Errorf("no chapters field in encoded book")
+	}
+	if _, err := w.Write(before); err != nil {
+		return err
+	}
+	open, end := `"chapters": [`, "]"
+	if compact {
+		open = `"chapters":[`
+	} else if len(book.Chapters) > 0 {
+		end = "\n  ]"
+	}
+	if _, err := io.WriteString(w, open); err != nil {
+		return err
+	}
+	for i, chapter := range book.Chapters {
+		var data []byte
+		if compact {
+			data, err = encodeCompact(chapter, glosses)
+		} else {
+			data, err = json.MarshalIndent(chapter, "    ", "  ")
+			data = joinLevels(data)
+		}
+		if err != nil {
+			return err
+		}
+		sep := ""
+		if i > 0 {
+			sep = ","
+		}
+		if !compact {
+			sep += "\n    "
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, end); err != nil {
+		return err
+	}
+	_, err = w.Write(after)
+	return err
+}
+
+// levelsArray matches the levels of a ref as json.MarshalIndent writes
+// them, one number to a line.
+var levelsArray = regexp.MustCompile(`"levels": \[[\s\d,]*\]`)
+
+// joinLevels writes the levels of each ref in indented JSON on one line,
+// as "levels": [1, 2], rather than a line to each number, which would make
+// refs a good part of an indented book.
+func joinLevels(data []byte) []byte {
+	return levelsArray.ReplaceAllFunc(data, func(levels []byte) []byte {
+		numbers := bytes.FieldsFunc(levels[len(`"levels": [`):len(levels)-1], func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		joined := append([]byte(`"levels": [`), bytes.Join(numbers, []byte(", "))...)
+		return append(joined, ']')
+	})
+}
+
+// compactHeader puts the encoding and gloss table ahead of the book's own
+// fields.
+type compactHeader struct {
+	Encoding string   `json:"encoding"`
+	Glosses  []string `json:"glosses"`
+	*Book
+}
+
+// glossTable numbers the distinct non-empty glosses of a book.
+type glossTable struct {
+	list  []string
+	index map[string]int
+}
+
+func newGlossTable(book *Book) *glossTable {
+	t := &glossTable{list: []string{}, index: map[string]int{}}
+	for _, chapter := range book.Chapters {
+		for _, item := range chapter.Content {
+			for _, p := range item.Paragraph {
+				for _, w := range p.Words {
+					if _, ok := t.index[w.Gloss]; !ok && w.Gloss != "" {
+						t.index[w.Gloss] = len(t.list)
+						t.list = append(t.list, w.Gloss)
+					}
+				}
+			}
+		}
+	}
+	return t
+}
+
+// encodeCompact writes a chapter in the compact encoding, in which a
+// word's gloss is left out when empty and otherwise given as "g", its index
+// in the table. With no table the gloss is kept as it is, as in the
+// chapter files of compact shards. Fields are written in the order the
+// chapter's own types give them.
+func encodeCompact(chapter *Chapter, glosses *glossTable) ([]byte, error) {
+	c := compactChapter{Chapter: chapter}
+	if chapter.Content != nil {
+		c.Content = make([]compactItem, len(chapter.Content))
+	}
+	for i := range chapter.Content {
+		item := &chapter.Content[i]
+		c.Content[i].ContentItem = item
+		if item.Paragraph != nil {
+			c.Content[i].Paragraph = make([]compactParagraph, len(item.Paragraph))
+		}
+		for j := range item.Paragraph {
+			p := &item.Paragraph[j]
+			c.Content[i].Paragraph[j].Paragraph = p
+			if p.Words != nil {
+				c.Content[i].Paragraph[j].Words = make([]compactWord, len(p.Words))
+			}
+			for k, w := range p.Words {
+				c.Content[i].Paragraph[j].Words[k] = glosses.word(w)
+			}
+		}
+	}
+	return json.Marshal(c)
+}
+
+// compactChapter, compactItem and compactParagraph hold a chapter's content
+// in the compact encoding. The field each one replaces is the last of its
+// type, so that fields keep their order.
+type compactChapter struct {
+	*Chapter
+	Content []compactItem `json:"content"`
+}
+
+type compactItem struct {
+	*ContentItem
+	Paragraph []compactParagraph `json:"paragraph"`
+}
+
+type compactParagraph struct {
+	*Paragraph
+	Words []compactWord `json:"words"`
+}
+
+// compactWord is a Word in the compact encoding: its fields in the same
+// order, with the gloss given as it is, as G, or not at all.
+type compactWord struct {
+	Word      string  `json:"word"`
+	Gloss     string  `json:"gloss,omitempty"`
+	G         *int    `json:"g,omitempty"`
+	Pre       string  `json:"pre,omitempty"`
+	Post      string  `json:"post,omitempty"`
+	Elision   string  `json:"elision,omitempty"`
+	Stephanus string  `json:"stephanus,omitempty"`
+	Markup    *Markup `json:"markup,omitempty"`
+}
+
+// word returns w in the compact encoding. A nil table keeps the gloss.
+func (t *glossTable) word(w Word) compactWord {
+	c := compactWord{Word: w.Word, Pre: w.Pre, Post: w.Post, Elision: w.Elision, Stephanus: w.Stephanus, Markup: w.Markup}
+	if w.Gloss == "" {
+		return c
+	}
+	if t == nil {
+		c.Gloss = w.Gloss
+	} else {
+		g := t.index[w.Gloss]
+		c.G = &g
+	}
+	return c
+}
+
+// ReadFile reads a book file written in any encoding, migrating it in
+// memory if it was written in an older version of the format.
+func ReadFile(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	defer f.Close()
+	book, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return book, nil
+}
+
+// Decode reads a book in any of the encodings Encode and WriteEncoded
+// produce, gzip-compressed or not, and migrates it to the current version
+// of the format.
+func Decode(r io.Reader) (*Book, error) {
+	decoded, err := decodeRaw(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Migrate(decoded); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+	book := &Book{}
+	if err := json.Unmarshal(data, book); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// decodeRaw reads a book file as plain JSON values, uncompressing it and
+// expanding the compact encoding as needed. Numbers are kept as
+// json.Number.
+func decodeRaw(r io.Reader) (map[string]any, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+	decoded := map[string]any{}
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	enc, ok := decoded["encoding"]
+	if !ok {
+		return decoded, nil
+	}
+	if enc != "compact" {
+		return nil, fmt.Errorf("unknown encoding %v", enc)
+	}
+	glosses := list(decoded["glosses"])
+	var bad error
+	eachWord(decoded, func(w map[string]any) {
+		w["gloss"] = ""
+		if g, ok := w["g"].(json.Number); ok {
+			n, err := g.Int64()
+			if err != nil || n < 0 || n >= int64(len(glosses)) {
+				bad = fmt.Errorf("gloss index %v out of range", g)
+				return
+			}
+			w["gloss"] = glosses[n]
+		}
+		delete(w, "g")
+	})
+	if bad != nil {
+		return nil, bad
+	}
+	delete(decoded, "encoding")
+	delete(decoded, "glosses")
+	return decoded, nil
+}
+
+// eachWord calls fn for each word of a decoded book or chapter.
+func eachWord(v map[string]any, fn func(map[string]any)) {
+	chapters := list(v["chapters"])
+	if chapters == nil {
+		chapters = []any{v}
+	}
+	for _, chapter := range chapters {
+		chapter, _ := chapter.(map[string]any)
+		for _, item := range list(chapter["content"]) {
+			item, _ := item.(map[string]any)
+			for _, p := range list(item["paragraph"]) {
+				p, _ := p.(map[string]any)
+				for _, w := range list(p["words"]) {
+					if w, ok := w.(map[string]any); ok {
+						fn(w)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testBook returns a small book using most of the format: glossed and
+// unglossed words, repeated glosses, refs, speakers, markup and parts.
+func testBook() *Book {
+	ref := func(levels ...int) *Ref {
+		r := NewRef(levels...)
+		return &r
+	}
+	first := NewChapter("chapter-1", "Κεφάλαιον αʹ")
+	first.Title.Gloss = "Chapter 1"
+	first.Number = 1
+	first.Vocab = []VocabItem{{Word: "λόγος", Gloss: "word", Image: ""}}
+	first.Questions = []Question{{Question: "Τίς;", Answer: "Who?"}}
+	first.Content = []ContentItem{{
+		Subtitle: "Section 1",
+		Speaker:  "ΣΩ",
+		Paragraph: []Paragraph{{
+			VerseID: 1,
+			Ref:     ref(1, 1),
+			Speaker: "ΣΩ",
+			Words: []Word{
+				{Word: "Ἐν", Gloss: "in", Pre: "«"},
+				{Word: "ἀρχῇ", Gloss: "beginning", Stephanus: "17a"},
+				{Word: "ἦν", Gloss: ""},
+				{Word: "ὁ", Gloss: "the"},
+				{Word: "λόγος", Gloss: "word", Post: ",»", Markup: &Markup{Quote: "quotation"}},
+			},
+		}},
+	}}
+	second := NewChapter("chapter-2", "Κεφάλαιον βʹ")
+	second.Content = []ContentItem{{
+		Paragraph: []Paragraph{{
+			VerseID: 2,
+			Ref:     ref(2, 1),
+			Words: []Word{
+				{Word: "δ’", Gloss: "and"},
+				{Word: "ὁ", Gloss: "the", Post: "·"},
+			},
+		}},
+	}}
+	return &Book{
+		SchemaVersion: SchemaVersion,
+		Title:         "Test",
+		Slug:          "test",
+		Author:        "Anonymous",
+		Language:      "Greek",
+		Description:   "A book for tests.",
+		Chapters:      []*Chapter{first, second},
+		Parts: []*Part{{
+			Slug:     "part-1",
+			Title:    Title{Display: "Μέρος αʹ", Gloss: "Part 1"},
+			Chapters: []string{"chapter-1", "chapter-2"},
+		}},
+		Speakers:  []Speaker{{Abbrev: "ΣΩ", Name: "Σωκράτης", Gloss: "Socrates"}},
+		Stephanus: []StephanusAnchor{{Stephanus: "17a", Chapter: "chapter-1", VerseID: 1, Word: 1}},
+	}
+}
+
+func TestEncodeIndented(t *testing.T) {
+	book := testBook()
+	var buf bytes.Buffer
+	if err := Encode(&buf, book, false); err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.MarshalIndent(book, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want = joinLevels(want); !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Encode gave\n%s\nwant\n%s", buf.Bytes(), want)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"levels": [2, 1],`)) {
+		t.Errorf("levels not written on one line:\n%s", buf.Bytes())
+	}
+}
+
+func TestJoinLevels(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"\"levels\": [\n  1,\n  12,\n  3\n]", "\"levels\": [1, 12, 3]"},
+		{"\"levels\": [\n      7\n    ],\n\"cite\": \"7\"", "\"levels\": [7],\n\"cite\": \"7\""},
+		{"\"levels\": []", "\"levels\": []"},
+		{"\"word\": \"levels\"", "\"word\": \"levels\""},
+	}
+	for _, tt := range tests {
+		if got := string(joinLevels([]byte(tt.in))); got != tt.want {
+			t.Errorf("joinLevels(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	for _, enc := range []Encoding{
+		{},
+		{Compact: true},
+		{Gzip: true},
+		{Compact: true, Gzip: true},
+	} {
+		path := filepath.Join(t.TempDir(), "test.json")
+		if err := WriteEncoded(path, testBook(), enc); err != nil {
+			t.Fatal(err)
+		}
+		paths := []string{path}
+		if enc.Gzip {
+			paths = append(paths, path+".gz")
+		}
+		for _, p := range paths {
+			got, err := ReadFile(p)
+			if err != nil {
+				t.Fatalf("%+v: %v", enc, err)
+			}
+			if want := testBook(); !reflect.DeepEqual(got, want) {
+				t.Errorf("%+v: %s read back differs: %v", enc, filepath.Base(p), Diff(want, got))
+			}
+		}
+	}
+}
+
+func TestWriteEncodedRemovesOtherEncodings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	if err := WriteEncoded(path, testBook(), Encoding{Gzip: true, Shards: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteEncoded(path, testBook(), Encoding{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, stale := range []string{path + ".gz", ShardDir(path)} {
+		if _, err := os.Stat(stale); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", filepath.Base(stale))
+		}
+	}
+}
+
+// TestEncodeCompactOrder checks that the compact encoding keeps fields in
+// the order of the book's types and leaves out empty glosses.
+func TestEncodeCompactOrder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, testBook(), true); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`{"encoding":"compact","glosses":["in","beginning","the","word","and"],"schemaVersion":`,
+		`"chapters":[{"slug":"chapter-1","title":{"display":"Κεφάλαιον αʹ","gloss":"Chapter 1"},"number":1,"titleImage":""`,
+		`{"subtitle":"Section 1","speaker":"ΣΩ","paragraph":[{"verse_id":1,"ref":{"levels":[1,1],"cite":"1.1"},"speaker":"ΣΩ","words":[`,
+		`{"word":"Ἐν","g":0,"pre":"«"},{"word":"ἀρχῇ","g":1,"stephanus":"17a"},{"word":"ἦν"},`,
+	} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("compact book does not contain %s:\n%s", want, buf.Bytes())
+		}
+	}
+}
+
+// TestCompactWordFields checks that compactWord has the fields of Word, in
+// the same order, so that no field of a word is lost from compact books.
+func TestCompactWordFields(t *testing.T) {
+	fields := func(v any, skip string) []string {
+		list := []string{}
+		typ := reflect.TypeOf(v)
+		for i := range typ.NumField() {
+			if f := typ.Field(i); f.Name != skip {
+				list = append(list, f.Name+" "+f.Type.String())
+			}
+		}
+		return list
+	}
+	if got, want := fields(compactWord{}, "G"), fields(Word{}, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("compactWord fields = %q, want those of Word, %q", got, want)
+	}
+}
+
+// failingWriter fails the write that goes past n bytes, and only that
+// one, so that an error ignored is not caught by a later write instead.
+type failingWriter struct {
+	n      int
+	failed bool
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n && !w.failed {
+		w.failed = true
+		return w.n, errors.New("disk full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestEncodeReturnsWriteErrors(t *testing.T) {
+	for _, compact := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, testBook(), compact); err != nil {
+			t.Fatal(err)
+		}
+		for n := 0; n < buf.Len(); n++ {
+			if err := Encode(&failingWriter{n: n}, testBook(), compact); err == nil {
+				t.Errorf("compact %t: no error when writing fails after %d of %d bytes", compact, n, buf.Len())
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package book
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write the rebuilt books over the committed ones")
+
+// TestGolden rebuilds every book in the repository and compares it with
+// its committed JSON, as grbook check does. Run it with -update to accept
+// the changes.
+func TestGolden(t *testing.T) {
+	manifests, err := FindManifests("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collections, err := FindCollections("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	covers := config.CoverOptions("..", collections)
+	covers.NoWrite = !*update
+	for _, m := range manifests {
+		rebuilt, _, err := Convert(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := AddCover(rebuilt, m, covers); err != nil {
+			t.Fatal(err)
+		}
+		path := m.OutputPath()
+		if *update {
+			if err := WriteFile(path, rebuilt); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			t.Errorf("%s: no committed book", path)
+			continue
+		}
+		committed, err := ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diffs := Diff(committed, rebuilt); len(diffs) > 0 {
+			if len(diffs) > 10 {
+				diffs = diffs[:10]
+			}
+			t.Errorf("%s differs from the rebuilt book:\n\t%s", path, strings.Join(diffs, "\n\t"))
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package book
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// Grouping says how to split a run of content items into groups: the
+// chapters of a book, or the sections of a chapter, each of which is
+// marked by a subtitle on its first item. Items are never split, so a
+// speaking turn always stays in one group.
+type Grouping struct {
+	// By is the strategy: "ref" groups the verses by the first level of
+	// their refs that differs within the run, so that the chapters of a
+	// book are its top-level refs and the sections of a chapter the refs
+	// one level down; "verses", "turns" and "words" close a group once it
+	// holds Size verses, speaking turns or words; "stephanus" groups by
+	// Stephanus page.
+	By string `json:"by"`
+	// Size is the number of refs, verses, turns or Stephanus pages in
+	// each group, or the number of words a group reaches before the next
+	// one starts. It defaults to 1, or for verses, turns and words to the
+	// entry in defaultSizes.
+	Size int `json:"size,omitempty"`
+	// Title is the template for the group's title, in which {n} is
+	// replaced by the group's number and {first} and {last} by the first
+	// and last ref, verse, turn or Stephanus section in it. Verses and
+	// turns are numbered from 1 within the run, and a group of refs runs
+	// over the whole batch, so that with a size of 10 the second group is
+	// always "11-20".
+	Title string `json:"title,omitempty"`
+	// Numerals is "greek" to write {n} in Greek numerals, as "Κεφάλαιον
+	// βʹ" for the second chapter, or "" for Arabic numerals.
+	Numerals string `json:"numerals,omitempty"`
+}
+
+var defaultSizes = map[string]int{
+	"ref":       1,
+	"stephanus": 1,
+	"verses":    20,
+	"turns":     10,
+	"words":     1000,
+}
+
+// group is one group of items and the labels of its first and last unit.
+type group struct {
+	items       []ContentItem
+	first, last string
+}
+
+func (g Grouping) check() error {
+	if _, ok := defaultSizes[g.By]; !ok {
+		return fmt.Errorf("unknown grouping %q", g.By)
+	}
+	if g.Size < 0 {
+		return fmt.Errorf("grouping %q: size must not be negative", g.By)
+	}
+	return checkNumerals(g.Numerals)
+}
+
+func (g Grouping) size() int {
+	if g.Size > 0 {
+		return g.Size
+	}
+	return defaultSizes[g.By]
+}
+
+// title fills in the group's title from the template, or from def when
+// the grouping has none.
+func (g Grouping) title(def string, n int, grp group) string {
+	template := g.Title
+	if template == "" {
+		template = def
+	}
+	num := strconv.Itoa(n)
+	if g.Numerals == "greek" {
+		num = greek.Numeral(n)
+	}
+	return strings.NewReplacer("{n}", num, "{first}", grp.first, "{last}", grp.last).Replace(template)
+}
+
+// chapterTitle titles the nth chapter from the template, or else as
+// "Chapter n", or "Κεφάλαιον" with Greek numerals.
+func (g Grouping) chapterTitle(n int, grp group) string {
+	if g.Numerals == "greek" {
+		return g.title("Κεφάλαιον {n}", n, grp)
+	}
+	return g.title("Chapter {n}", n, grp)
+}
+
+// headingGlosses are the English of the words that chapter titles number
+// in Greek numerals, keyed by their form under greek.Fold.
+var headingGlosses = map[string]string{
+	"ορασισ":     "Vision",
+	"αποκαλυψισ": "Revelation",
+	"εντολη":     "Mandate",
+	"παραβολη":   "Similitude",
+	"κεφαλαιον":  "Chapter",
+	"βιβλιον":    "Book",
+	"λογοσ":      "Book",
+	"επιστολη":   "Letter",
+	"μεροσ":      "Part",
+}
+
+// numberChapters sets the Number of each chapter whose title ends in a
+// Greek numeral, such as "Ὅρασις αʹ", and glosses a title without a gloss
+// that is a word of headingGlosses and a numeral as "Vision 1".
+func numberChapters(book *Book) {
+	for _, c := range book.Chapters {
+		word, n, ok := greek.SplitNumeral(c.Title.Display)
+		if !ok {
+			continue
+		}
+		c.Number = n
+		if gloss, ok := headingGlosses[greek.Fold(word)]; ok && c.Title.Gloss == "" {
+			c.Title.Gloss = fmt.Sprintf("%s %d", gloss, n)
+		}
+	}
+}
+
+func checkNumerals(numerals string) error {
+	if numerals != "" && numerals != "greek" {
+		return fmt.Errorf("unknown numerals %q", numerals)
+	}
+	return nil
+}
+
+// split divides items into groups.
+func (g Grouping) split(items []ContentItem) []group {
+	size := g.size()
+	level := refLevel(items)
+	groups := []group{}
+	var cur *group
+	// batch is the current group's ref batch or first Stephanus page, and
+	// count the verses, turns or words in it so far. verse and turn
+	// number the units of the whole run.
+	batch, count, verse, turn := -1, 0, 0, 0
+	for _, item := range items {
+		start := cur == nil
+		first, last := "", ""
+		switch g.By {
+		case "ref":
+			if ref := firstRef(item); ref != nil && level < len(ref.Levels) {
+				b := (ref.Levels[level] - 1) / size
+				if b != batch {
+					start = true
+					batch = b
+				}
+				first = strconv.Itoa(b*size + 1)
+				last = strconv.Itoa((b + 1) * size)
+			}
+		case "stephanus":
+			for _, p := range item.Paragraph {
+				s, err := ParseStephanus(p.Stephanus)
+				if err != nil {
+					continue
+				}
+				if first == "" {
+					first = p.Stephanus
+					if batch < 0 || s.Page >= batch+size {
+						start = true
+						batch = s.Page
+					}
+				}
+				last = p.Stephanus
+			}
+		case "verses", "words":
+			if count >= size {
+				start = true
+			}
+		case "turns":
+			turn++
+			first, last = strconv.Itoa(turn), strconv.Itoa(turn)
+			if count >= size {
+				start = true
+			}
+		}
+		if g.By == "verses" || g.By == "words" {
+			for _, p := range item.Paragraph {
+				if p.Ref == nil {
+					continue
+				}
+				verse++
+				if first == "" {
+					first = strconv.Itoa(verse)
+				}
+				last = strconv.Itoa(verse)
+			}
+		}
+		if start {
+			groups = append(groups, group{})
+			cur = &groups[len(groups)-1]
+			count = 0
+		}
+		cur.items = append(cur.items, item)
+		if cur.first == "" {
+			cur.first = first
+		}
+		if last != "" {
+			cur.last = last
+		}
+		switch g.By {
+		case "verses":
+			count += countVerses(item)
+		case "turns":
+			count++
+		case "words":
+			count += countWords(item)
+		}
+	}
+	return groups
+}
+
+// refLevel returns the first level at which the refs of items differ.
+func refLevel(items []ContentItem) int {
+	var prefix []int
+	shortest := 0
+	for _, item := range items {
+		for _, p := range item.Paragraph {
+			if p.Ref == nil {
+				continue
+			}
+			if prefix == nil {
+				prefix = p.Ref.Levels
+				shortest = len(prefix)
+				continue
+			}
+			shortest = min(shortest, len(p.Ref.Levels))
+			n := 0
+			for n < len(prefix) && n < len(p.Ref.Levels) && prefix[n] == p.Ref.Levels[n] {
+				n++
+			}
+			prefix = prefix[:n]
+		}
+	}
+	// A run of one ref is grouped by its last level.
+	return min(len(prefix), max(shortest-1, 0))
+}
+
+func firstRef(item ContentItem) *Ref {
+	for _, p := range item.Paragraph {
+		if p.Ref != nil {
+			return p.Ref
+		}
+	}
+	return nil
+}
+
+func countVerses(item ContentItem) int {
+	n := 0
+	for _, p := range item.Paragraph {
+		if p.Ref != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func countWords(item ContentItem) int {
+	n := 0
+	for _, p := range item.Paragraph {
+		for _, w := range p.Words {
+			if w.Word != "" {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// splitChapters regroups the content of a book into chapters. Each new
+// chapter goes into the part that held its first item.
+func splitChapters(book *Book, g Grouping) {
+	parts := partOf(book.Parts)
+	eachPart(book.Parts, func(p *Part) { p.Chapters = []string{} })
+	items := []ContentItem{}
+	itemParts := []*Part{}
+	for _, chapter := range book.Chapters {
+		items = append(items, chapter.Content...)
+		for range chapter.Content {
+			itemParts = append(itemParts, parts[chapter.Slug])
+		}
+	}
+	book.Chapters = []*Chapter{}
+	n := 0
+	for i, grp := range g.split(items) {
+		chapter := NewChapter(fmt.Sprintf("chapter-%d", i+1), g.chapterTitle(i+1, grp))
+		chapter.Content = grp.items
+		book.Chapters = append(book.Chapters, chapter)
+		if part := itemParts[n]; part != nil {
+			part.Chapters = append(part.Chapters, chapter.Slug)
+		}
+		n += len(grp.items)
+	}
+}
+
+// splitSections marks the sections of each chapter with a subtitle on
+// their first item. A subtitle the source gives is kept.
+func splitSections(book *Book, g Grouping) {
+	for _, chapter := range book.Chapters {
+		groups := g.split(chapter.Content)
+		content := []ContentItem{}
+		for i, grp := range groups {
+			def := "Section {first}-{last}"
+			if grp.first == grp.last {
+				def = "Section {first}"
+			}
+			if grp.items[0].Subtitle == "" {
+				grp.items[0].Subtitle = g.title(def, i+1, grp)
+			}
+			content = append(content, grp.items...)
+		}
+		chapter.Content = content
+	}
+}
@@ -0,0 +1,153 @@
+package book
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// verse returns a verse of the given ref and number of words.
+func verse(ref Ref, words int) Paragraph {
+	p := Paragraph{Ref: &ref}
+	for range words {
+		p.Words = append(p.Words, Word{Word: "λόγος"})
+	}
+	return p
+}
+
+// describeGroups writes each group as its first and last label and the
+// number of items in it, as "1-2:2".
+func describeGroups(groups []group) []string {
+	list := []string{}
+	for _, g := range groups {
+		list = append(list, fmt.Sprintf("%s-%s:%d", g.first, g.last, len(g.items)))
+	}
+	return list
+}
+
+func TestSplit(t *testing.T) {
+	// Five items of one verse of three words each.
+	single := []ContentItem{}
+	for i := 1; i <= 5; i++ {
+		single = append(single, ContentItem{Paragraph: []Paragraph{verse(NewRef(1, i), 3)}})
+	}
+	// Four speaking turns of two, one, three and one verses.
+	turns := []ContentItem{}
+	n := 0
+	for _, verses := range []int{2, 1, 3, 1} {
+		item := ContentItem{Speaker: "ΣΩ."}
+		for range verses {
+			n++
+			item.Paragraph = append(item.Paragraph, verse(NewRef(1, n), 2))
+		}
+		turns = append(turns, item)
+	}
+	// Items at Stephanus pages 17 to 20, one with no section and one with
+	// two.
+	pages := []ContentItem{}
+	for i, sections := range [][]string{{"17a"}, {"17b", "18a"}, {""}, {"19c"}, {"20a"}} {
+		item := ContentItem{}
+		for _, s := range sections {
+			p := verse(NewRef(1, i+1), 1)
+			p.Stephanus = s
+			item.Paragraph = append(item.Paragraph, p)
+		}
+		pages = append(pages, item)
+	}
+	tests := []struct {
+		grouping Grouping
+		items    []ContentItem
+		want     []string
+	}{
+		{Grouping{By: "verses", Size: 2}, single, []string{"1-2:2", "3-4:2", "5-5:1"}},
+		{Grouping{By: "verses"}, single, []string{"1-5:5"}},
+		// A turn is never split, so a group may hold more than Size verses.
+		{Grouping{By: "verses", Size: 2}, turns, []string{"1-2:1", "3-6:2", "7-7:1"}},
+		{Grouping{By: "turns", Size: 2}, turns, []string{"1-2:2", "3-4:2"}},
+		{Grouping{By: "turns", Size: 3}, turns, []string{"1-3:3", "4-4:1"}},
+		// A group of words closes once it reaches Size, not before.
+		{Grouping{By: "words", Size: 5}, single, []string{"1-2:2", "3-4:2", "5-5:1"}},
+		{Grouping{By: "words", Size: 6}, single, []string{"1-2:2", "3-4:2", "5-5:1"}},
+		{Grouping{By: "words", Size: 7}, single, []string{"1-3:3", "4-5:2"}},
+		{Grouping{By: "words", Size: 5}, turns, []string{"1-3:2", "4-6:1", "7-7:1"}},
+		{Grouping{By: "stephanus"}, pages, []string{"17a-18a:3", "19c-19c:1", "20a-20a:1"}},
+		{Grouping{By: "stephanus", Size: 2}, pages, []string{"17a-18a:3", "19c-20a:2"}},
+		{Grouping{By: "stephanus", Size: 3}, pages, []string{"17a-19c:4", "20a-20a:1"}},
+		{Grouping{By: "ref", Size: 2}, single, []string{"1-2:2", "3-4:2", "5-6:1"}},
+		{Grouping{By: "verses"}, nil, []string{}},
+	}
+	for _, tt := range tests {
+		if got := describeGroups(tt.grouping.split(tt.items)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("split by %s of %d (%d items) = %q, want %q", tt.grouping.By, tt.grouping.Size, len(tt.items), got, tt.want)
+		}
+	}
+}
+
+func TestGroupingCheck(t *testing.T) {
+	for _, g := range []Grouping{{By: "ref"}, {By: "verses", Size: 10}, {By: "turns"}, {By: "words", Numerals: "greek"}, {By: "stephanus"}} {
+		if err := g.check(); err != nil {
+			t.Errorf("check(%+v) = %v", g, err)
+		}
+	}
+	for _, g := range []Grouping{{By: "pages"}, {By: ""}, {By: "verses", Size: -1}, {By: "turns", Numerals: "roman"}} {
+		if err := g.check(); err == nil {
+			t.Errorf("check(%+v) passed, want an error", g)
+		}
+	}
+}
+
+func TestGroupingTitle(t *testing.T) {
+	grp := group{first: "3", last: "4"}
+	tests := []struct {
+		grouping Grouping
+		want     string
+	}{
+		{Grouping{}, "Chapter 2"},
+		{Grouping{Numerals: "greek"}, "Κεφάλαιον βʹ"},
+		{Grouping{Title: "Λόγος {n}", Numerals: "greek"}, "Λόγος βʹ"},
+		{Grouping{Title: "Verses {first}-{last}"}, "Verses 3-4"},
+	}
+	for _, tt := range tests {
+		if got := tt.grouping.chapterTitle(2, grp); got != tt.want {
+			t.Errorf("chapterTitle with %+v = %q, want %q", tt.grouping, got, tt.want)
+		}
+	}
+}
+
+// TestGroupingManifest checks each strategy from the manifest through
+// Convert, which titles each chapter from its group or marks each section
+// with a subtitle.
+func TestGroupingManifest(t *testing.T) {
+	source := "001.00 {17a} ΣΩ.\n001.01 Ὅτι μὲν ὑμεῖς, ὦ ἄνδρες Ἀθηναῖοι,\n001.02 πεπόνθατε ὑπὸ τῶν ἐμῶν κατηγόρων,\n" +
+		"002.00 ΜΕ.\n002.01 {17b} οὐκ οἶδα.\n003.00 ΣΩ.\n003.01 {18a} ἐγὼ δ’ οὖν\n003.02 καὶ αὐτὸς\n"
+	tests := []struct {
+		manifest string
+		want     []string
+	}{
+		{`"grouping": "verses", "groupSize": 2, "chapterTitle": "Verses {first}-{last}"`, []string{"Verses 1-2", "Verses 3-5"}},
+		{`"grouping": "turns", "groupSize": 2`, []string{"Chapter 1", "Chapter 2"}},
+		{`"grouping": "words", "groupSize": 8, "numerals": "greek"`, []string{"Κεφάλαιον αʹ", "Κεφάλαιον βʹ"}},
+		{`"grouping": "stephanus", "chapterTitle": "{first}"`, []string{"17a", "18a"}},
+		{`"sections": {"by": "turns", "size": 1}`, []string{"Section 1", "Section 2", "Section 3"}},
+		{`"sections": {"by": "stephanus", "title": "Stephanus {first}"}`, []string{"Stephanus 17a", "Stephanus 18a"}},
+	}
+	for _, tt := range tests {
+		b, _ := convertSource(t, `{"title": "Test", "dialogue": true, `+tt.manifest+`}`, source)
+		got := []string{}
+		for _, c := range b.Chapters {
+			if !strings.Contains(tt.manifest, "sections") {
+				got = append(got, c.Title.Display)
+				continue
+			}
+			for _, item := range c.Content {
+				if item.Subtitle != "" {
+					got = append(got, item.Subtitle)
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.manifest, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ManifestName is the file that marks a directory as holding a book.
+const ManifestName = "grbook.json"
+
+// Manifest describes one book directory: its metadata and how its source
+// text should be converted.
+type Manifest struct {
+	Title       string `json:"title"`
+	GreekTitle  string `json:"greekTitle,omitempty"`
+	Slug        string `json:"slug"`
+	Author      string `json:"author"`
+	Language    string `json:"language,omitempty"`
+	Description string `json:"description,omitempty"`
+	Cover       string `json:"cover,omitempty"`
+	Restricted  bool   `json:"restricted,omitempty"`
+	// Collection names the shelf the book is listed under in the
+	// catalog, if it is not in the directory of a Collection, and
+	// defaults to the author. Level overrides the difficulty
+	// level the catalog works out from the book's vocabulary.
+	Collection string `json:"collection,omitempty"`
+	Level      int    `json:"level,omitempty"`
+
+	// Source and Output are relative to the manifest's directory and
+	// default to <slug>.txt and <slug>.json.
+	Source string `json:"source,omitempty"`
+	Output string `json:"output,omitempty"`
+
+	// Format is "" for a text of numbered lines, read with Scheme and
+	// Grouping, or "sections" for a hand-authored file in the sectioned
+	// format described in section.go.
+	Format string `json:"format,omitempty"`
+	// Scheme names the reference scheme used by the source lines.
+	Scheme string `json:"scheme,omitempty"`
+	// Grouping selects how verses are split into chapters: "" keeps the
+	// whole book in one chapter, "top" starts a chapter per top-level ref
+	// and "titles" a chapter at each title line. Any strategy of a
+	// Grouping may also be named, with GroupSize as its size. ChapterTitle
+	// is the template for chapter titles the source does not give, as in
+	// Grouping.Title; it defaults to "Chapter {n}", where for "top" {n} is
+	// the top-level ref.
+	Grouping     string `json:"grouping,omitempty"`
+	GroupSize    int    `json:"groupSize,omitempty"`
+	ChapterTitle string `json:"chapterTitle,omitempty"`
+	// Numerals is "greek" to number the chapter and section titles the
+	// source does not give in Greek numerals, as Grouping.Numerals; the
+	// default chapter title is then "Κεφάλαιον {n}".
+	Numerals string `json:"numerals,omitempty"`
+	// Sections, if set, splits each chapter into sections titled by a
+	// subtitle, such as the "Section 1-10" of each book of the Republic.
+	Sections *Grouping `json:"sections,omitempty"`
+	// Parts declares the parts of a book whose source does not mark them.
+	Parts []ManifestPart `json:"parts,omitempty"`
+	// Volumes, if set, makes the book a work of several volumes, each
+	// converted from its own manifest and source and joined into one
+	// book by convertVolumes. The work's own Source, Scheme and Grouping
+	// are not used, but its Sections are. Each volume remains a book of
+	// its own as well.
+	Volumes []ManifestVolume `json:"volumes,omitempty"`
+	// PrefixRefs puts each volume's number in front of its refs, for a
+	// work whose volumes number their refs afresh. Volumes whose refs
+	// already carry the volume's number, as the books of the Paidagogos
+	// do, are left as they are.
+	PrefixRefs bool `json:"prefixRefs,omitempty"`
+	// Dialogue marks the book as a dialogue, whose source gives each
+	// change of speaker as a verse numbered 0 holding only the speaker's
+	// abbreviation, such as "002.00 ΚΡ.". Other books keep such verses as
+	// they are.
+	Dialogue bool `json:"dialogue,omitempty"`
+	// Speakers adds to or overrides the built-in table of dialogue
+	// speakers, keyed by abbreviation.
+	Speakers map[string]Speaker `json:"speakers,omitempty"`
+
+	// Dir is the book's directory, and File the file the manifest was
+	// read from: its grbook.json, or for an omnibus its collection's
+	// collection.json.
+	Dir     string `json:"-"`
+	File    string `json:"-"`
+	omnibus *Collection
+}
+
+// LoadManifest reads the manifest in dir and fills in its defaults.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %v", err)
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", filepath.Join(dir, ManifestName), err)
+	}
+	m.Dir = dir
+	m.File = filepath.Join(dir, ManifestName)
+	if m.Slug == "" {
+		m.Slug = filepath.Base(dir)
+	}
+	if m.Title == "" {
+		m.Title = m.Slug
+	}
+	if m.Collection == "" {
+		m.Collection = m.Author
+	}
+	if m.Language == "" {
+		m.Language = "Greek"
+	}
+	if m.Source == "" {
+		m.Source = m.Slug + ".txt"
+	}
+	if m.Output == "" {
+		m.Output = m.Slug + ".json"
+	}
+	return m, nil
+}
+
+// FindManifests walks root and loads every manifest below it, together with
+// the manifest of the omnibus of every collection below it, sorted by
+// directory.
+func FindManifests(root string) ([]*Manifest, error) {
+	dirs := []string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != root && d.Name()[0] == '.' {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == ManifestName {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+	manifests := []*Manifest{}
+	for _, dir := range dirs {
+		m, err := LoadManifest(dir)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m)
+	}
+	collections, err := FindCollections(root)
+	if err != nil {
+		return nil, err
+	}
+	omnibuses := []*Manifest{}
+	for _, c := range collections {
+		m, err := c.OmnibusManifest(manifests)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			omnibuses = append(omnibuses, m)
+		}
+	}
+	manifests = append(manifests, omnibuses...)
+	sort.SliceStable(manifests, func(i, j int) bool { return manifests[i].Dir < manifests[j].Dir })
+	return manifests, nil
+}
+
+// SourcePath returns the path of the book's source text.
+func (m *Manifest) SourcePath() string {
+	return filepath.Join(m.Dir, m.Source)
+}
+
+// OutputPath returns the path the converted book is written to.
+func (m *Manifest) OutputPath() string {
+	return filepath.Join(m.Dir, m.Output)
+}
+
+// NewBook returns an empty book carrying the manifest's metadata.
+func (m *Manifest) NewBook() *Book {
+	return &Book{
+		SchemaVersion: SchemaVersion,
+		Title:         m.Title,
+		Slug:          m.Slug,
+		Author:        m.Author,
+		Language:      m.Language,
+		Description:   m.Description,
+		CoverImage:    m.Cover,
+		Restricted:    m.Restricted,
+		Chapters:      []*Chapter{},
+	}
+}
@@ -0,0 +1,168 @@
+package book
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// Markup records the editorial markup that applies to a word. The Plato
+// sources carry TEI-derived tags such as "{pers} Κρίτων {/pers}", which
+// are removed from the text and recorded here instead.
+type Markup struct {
+	// Entity is "person", "place", "name" or "reference".
+	Entity string `json:"entity,omitempty"`
+	// Quote is "speech" for {q} and "quotation" for {quote}.
+	Quote string `json:"quote,omitempty"`
+	// Editorial is "deleted", "added", "sic" or "corrected".
+	Editorial string `json:"editorial,omitempty"`
+	// Sic holds the original reading a corrected word replaces, when a
+	// {corr} span directly follows a {sic} span.
+	Sic string `json:"sic,omitempty"`
+	// Lacuna marks a gap in the text just before this word.
+	Lacuna bool `json:"lacuna,omitempty"`
+	// Break marks the start of a new paragraph at this word.
+	Break bool `json:"break,omitempty"`
+}
+
+// spanTags maps each paired tag to the markup it applies to its words.
+var spanTags = map[string]func(*Markup){
+	"pers":  func(m *Markup) { m.Entity = "person" },
+	"place": func(m *Markup) { m.Entity = "place" },
+	"name":  func(m *Markup) { m.Entity = "name" },
+	"rs":    func(m *Markup) { m.Entity = "reference" },
+	"q":     func(m *Markup) { m.Quote = "speech" },
+	"quote": func(m *Markup) { m.Quote = "quotation" },
+	"del":   func(m *Markup) { m.Editorial = "deleted" },
+	"add":   func(m *Markup) { m.Editorial = "added" },
+	"sic":   func(m *Markup) { m.Editorial = "sic" },
+	"corr":  func(m *Markup) { m.Editorial = "corrected" },
+}
+
+// markupTag matches editorial tags and the Stephanus milestones, such as
+// {43a}, that mark page and section breaks in the Plato sources.
+var markupTag = regexp.MustCompile(`\{(/?)([a-z]+|[0-9]+[a-e])\}`)
+
+type openTag struct {
+	name string
+	line int
+}
+
+// markupParser turns the text of successive verses into words, keeping
+// track of tags that stay open from one verse to the next.
+type markupParser struct {
+	stack []openTag
+
+	// stephanus is a milestone waiting for the word it anchors, and
+	// section the Stephanus section the text has reached.
+	stephanus string
+	section   string
+
+	lacuna   bool
+	brk      bool
+	sic      []string // words of the current {sic} span
+	lastSic  string   // a {sic} span that has just closed
+	inCorr   string   // the reading replaced by the open {corr} span
+	tokenRun bool     // whether a word has been seen since {/sic}
+}
+
+// words splits text into words, applying the markup of the tags around
+// them. line is used in errors.
+func (p *markupParser) words(text string, line int) ([]Word, error) {
+	words := &wordList{}
+	pos := 0
+	for _, loc := range markupTag.FindAllStringSubmatchIndex(text, -1) {
+		words.add(text[pos:loc[0]], p.word)
+		pos = loc[1]
+		closing := text[loc[2]:loc[3]] == "/"
+		name := text[loc[4]:loc[5]]
+		if err := p.tag(name, closing, line); err != nil {
+			return nil, err
+		}
+	}
+	words.add(text[pos:], p.word)
+	return words.done(), nil
+}
+
+func (p *markupParser) tag(name string, closing bool, line int) error {
+	if stephanusRegex.MatchString(name) {
+		p.stephanus = name
+		p.section = name
+		return nil
+	}
+	switch name {
+	case "gap":
+		p.lacuna = true
+		return nil
+	case "p":
+		p.brk = true
+		return nil
+	}
+	if spanTags[name] == nil {
+		return fmt.Errorf("unknown tag {%s}", name)
+	}
+	if !closing {
+		if name == "corr" && p.lastSic != "" && !p.tokenRun {
+			p.inCorr = p.lastSic
+		}
+		p.stack = append(p.stack, openTag{name: name, line: line})
+		return nil
+	}
+	if len(p.stack) == 0 {
+		return fmt.Errorf("{/%s} has no matching {%s}", name, name)
+	}
+	top := p.stack[len(p.stack)-1]
+	if top.name != name {
+		return fmt.Errorf("{/%s} closes {%s} opened on line %d", name, top.name, top.line)
+	}
+	p.stack = p.stack[:len(p.stack)-1]
+	switch name {
+	case "sic":
+		p.lastSic = strings.Join(p.sic, " ")
+		p.sic = nil
+		p.tokenRun = false
+	case "corr":
+		p.inCorr = ""
+		p.lastSic = ""
+	}
+	return nil
+}
+
+func (p *markupParser) word(t greek.Token) Word {
+	word := Word{
+		Word:      t.Form,
+		Gloss:     "",
+		Pre:       t.Pre,
+		Post:      t.Post,
+		Elision:   t.Elision,
+		Stephanus: p.stephanus,
+	}
+	p.stephanus = ""
+	m := &Markup{Lacuna: p.lacuna, Break: p.brk}
+	for _, open := range p.stack {
+		spanTags[open.name](m)
+		if open.name == "sic" {
+			p.sic = append(p.sic, t.String())
+		}
+		if open.name == "corr" {
+			m.Sic = p.inCorr
+		}
+	}
+	p.lacuna, p.brk = false, false
+	p.tokenRun = true
+	if *m != (Markup{}) {
+		word.Markup = m
+	}
+	return word
+}
+
+// finish reports tags left open at the end of the text.
+func (p *markupParser) finish() error {
+	if len(p.stack) > 0 {
+		top := p.stack[len(p.stack)-1]
+		return fmt.Errorf("{%s} opened on line %d is never closed", top.name, top.line)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package book
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// parseMarkup reads lines as successive verses and returns their words, or
+// the first error, finish's included.
+func parseMarkup(lines []string) ([]Word, error) {
+	p := &markupParser{}
+	words := []Word{}
+	for i, line := range lines {
+		ws, err := p.words(line, i+1)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, ws...)
+	}
+	return words, p.finish()
+}
+
+func TestMarkup(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []Word
+	}{
+		{"entity", []string{"{pers} Κρίτων {/pers} λέγει"}, []Word{
+			{Word: "Κρίτων", Markup: &Markup{Entity: "person"}},
+			{Word: "λέγει"},
+		}},
+		{"nested", []string{"{q} ὦ {pers} Σώκρατες, {/pers} ἄκουε {/q}"}, []Word{
+			{Word: "ὦ", Markup: &Markup{Quote: "speech"}},
+			{Word: "Σώκρατες", Post: ",", Markup: &Markup{Quote: "speech", Entity: "person"}},
+			{Word: "ἄκουε", Markup: &Markup{Quote: "speech"}},
+		}},
+		{"across verses", []string{"{quote} ἐν ἀρχῇ", "ἦν {/quote} ὁ"}, []Word{
+			{Word: "ἐν", Markup: &Markup{Quote: "quotation"}},
+			{Word: "ἀρχῇ", Markup: &Markup{Quote: "quotation"}},
+			{Word: "ἦν", Markup: &Markup{Quote: "quotation"}},
+			{Word: "ὁ"},
+		}},
+		{"sic and corr", []string{"{sic} τοῦτο {/sic} {corr} ταῦτα {/corr} λέγω"}, []Word{
+			{Word: "τοῦτο", Markup: &Markup{Editorial: "sic"}},
+			{Word: "ταῦτα", Markup: &Markup{Editorial: "corrected", Sic: "τοῦτο"}},
+			{Word: "λέγω"},
+		}},
+		{"corr apart from sic", []string{"{sic} α {/sic} β {corr} γ {/corr}"}, []Word{
+			{Word: "α", Markup: &Markup{Editorial: "sic"}},
+			{Word: "β"},
+			{Word: "γ", Markup: &Markup{Editorial: "corrected"}},
+		}},
+		{"deleted and added", []string{"{del} καὶ {/del} {add} δὲ {/add}"}, []Word{
+			{Word: "καὶ", Markup: &Markup{Editorial: "deleted"}},
+			{Word: "δὲ", Markup: &Markup{Editorial: "added"}},
+		}},
+		{"gap and paragraph", []string{"ἐν {gap} ἀρχῇ {p} ἦν"}, []Word{
+			{Word: "ἐν"},
+			{Word: "ἀρχῇ", Markup: &Markup{Lacuna: true}},
+			{Word: "ἦν", Markup: &Markup{Break: true}},
+		}},
+		{"stephanus", []string{"{17a} Ὅτι μὲν"}, []Word{
+			{Word: "Ὅτι", Stephanus: "17a"},
+			{Word: "μὲν"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := parseMarkup(tt.lines)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.name, describeWords(got), describeWords(tt.want))
+		}
+	}
+}
+
+func TestMarkupErrors(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{[]string{"{foo} α"}, "unknown tag {foo}"},
+		{[]string{"α {/pers}"}, "{/pers} has no matching {pers}"},
+		{[]string{"α", "β", "{pers} γ {/q}"}, "{/q} closes {pers} opened on line 3"},
+		{[]string{"{q} α {pers} β", "γ {/q} {/pers}"}, "{/q} closes {pers} opened on line 1"},
+		{[]string{"{q} α", "β"}, "{q} opened on line 1 is never closed"},
+		{[]string{"{q} {sic} α {/sic}"}, "{q} opened on line 1 is never closed"},
+	}
+	for _, tt := range tests {
+		_, err := parseMarkup(tt.lines)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%q: got error %v, want %q", tt.lines, err, tt.want)
+		}
+	}
+}
+
+// describeWords formats words with their markup for test failures.
+func describeWords(words []Word) string {
+	s := ""
+	for _, w := range words {
+		s += "\n\t" + w.Written()
+		if w.Stephanus != "" {
+			s += " stephanus=" + w.Stephanus
+		}
+		if w.Markup != nil {
+			data, _ := json.Marshal(w.Markup)
+			s += " " + string(data)
+		}
+	}
+	return s
+}
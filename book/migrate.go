@@ -0,0 +1,244 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// SchemaVersion is the version of the book format that Convert writes. It
+// goes up whenever a change to the format needs existing files to be
+// migrated.
+const SchemaVersion = 3
+
+// A Migration upgrades a decoded book file from version From to From+1.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(book map[string]any) error
+}
+
+// migrations holds one step for each version before SchemaVersion, in
+// order.
+var migrations = []Migration{
+	{
+		From:        0,
+		Description: "convert the legacy id/restricted shape with string vocab and questions",
+		Apply:       migrateLegacy,
+	},
+	{
+		From:        1,
+		Description: "split punctuation and elision marks off words",
+		Apply:       migrateWords,
+	},
+	{
+		From:        2,
+		Description: "move part markers out of the chapters into parts",
+		Apply:       migrateParts,
+	},
+}
+
+// Version returns the schema version of a decoded book file. Files written
+// before versioning have no schemaVersion: those in the legacy shape of
+// the JustinMartyr converter are version 0 and the rest version 1.
+func Version(book map[string]any) (int, error) {
+	if v, ok := book["schemaVersion"]; ok {
+		n, err := strconv.Atoi(fmt.Sprint(v))
+		if err != nil {
+			return 0, fmt.Errorf("invalid schemaVersion %v", v)
+		}
+		return n, nil
+	}
+	if _, ok := book["id"]; ok {
+		return 0, nil
+	}
+	if _, ok := book["restricted"]; ok {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// Migrate upgrades a decoded book file to SchemaVersion in place and
+// returns the descriptions of the steps it applied. It refuses files newer
+// than SchemaVersion.
+func Migrate(book map[string]any) ([]string, error) {
+	version, err := Version(book)
+	if err != nil {
+		return nil, err
+	}
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("schema version %d is newer than %d, the latest this grbook supports", version, SchemaVersion)
+	}
+	applied := []string{}
+	for _, m := range migrations[version:] {
+		if err := m.Apply(book); err != nil {
+			return nil, fmt.Errorf("migrating from version %d: %v", m.From, err)
+		}
+		applied = append(applied, m.Description)
+		book["schemaVersion"] = m.From + 1
+	}
+	return applied, nil
+}
+
+// MigrateFile upgrades the book file at path to SchemaVersion, rewriting it
+// as indented JSON only if write is set. A file that would lose any of its
+// fields in the upgrade is an error and is left untouched.
+func MigrateFile(path string, write bool) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	decoded, err := decodeRaw(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	applied, err := Migrate(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(applied) == 0 {
+		return applied, nil
+	}
+	migrated, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(migrated))
+	dec.DisallowUnknownFields()
+	book := &Book{}
+	if err := dec.Decode(book); err != nil {
+		return nil, fmt.Errorf("%s: migrated book does not fit the current format: %v", path, err)
+	}
+	if write {
+		return applied, WriteFile(path, book)
+	}
+	return applied, nil
+}
+
+// migrateLegacy converts the shape written by the old JustinMartyr
+// converter. Its id always repeated the slug, and its vocab and questions
+// were plain strings.
+func migrateLegacy(book map[string]any) error {
+	if id, ok := book["id"]; ok {
+		if slug, _ := book["slug"].(string); slug == "" {
+			book["slug"] = id
+		} else if id != slug {
+			return fmt.Errorf("id %q differs from slug %q", id, slug)
+		}
+		delete(book, "id")
+	}
+	for _, chapter := range list(book["chapters"]) {
+		chapter, _ := chapter.(map[string]any)
+		for i, v := range list(chapter["vocab"]) {
+			if word, ok := v.(string); ok {
+				chapter["vocab"].([]any)[i] = map[string]any{"word": word, "gloss": "", "image": ""}
+			}
+		}
+		for i, q := range list(chapter["questions"]) {
+			if question, ok := q.(string); ok {
+				chapter["questions"].([]any)[i] = map[string]any{"question": question, "answer": ""}
+			}
+		}
+	}
+	return nil
+}
+
+// migrateWords splits the punctuation that older files left attached to
+// words into pre and post and normalizes what is left, as Convert does for
+// new ones. The elision mark a word was written with is kept in elision,
+// as normalizing writes every mark as ’.
+func migrateWords(book map[string]any) error {
+	eachWord(book, func(w map[string]any) {
+		text, _ := w["word"].(string)
+		tokens := greek.Tokenize(text)
+		if len(tokens) != 1 || tokens[0].Form == "" {
+			return
+		}
+		t := tokens[0]
+		w["word"] = greek.Normalize(t.Form)
+		for key, value := range map[string]string{"pre": greek.Normalize(t.Pre), "post": greek.Normalize(t.Post), "elision": t.Elision} {
+			if value != "" {
+				w[key] = value
+			}
+		}
+	})
+	return nil
+}
+
+// migrateParts turns the paragraphs with verse_id 0 and no ref that older
+// converters put at the start of a chapter to name the part it begins,
+// such as Stoffel's "FIRST PART", into parts holding the chapters that
+// follow.
+func migrateParts(book map[string]any) error {
+	parts := []any{}
+	var part map[string]any
+	for _, chapter := range list(book["chapters"]) {
+		chapter, _ := chapter.(map[string]any)
+		content := []any{}
+		for _, item := range list(chapter["content"]) {
+			title, ok := partMarker(item)
+			if !ok {
+				content = append(content, item)
+				continue
+			}
+			if part != nil && len(list(part["chapters"])) == 0 {
+				t := part["title"].(map[string]any)
+				t["display"] = fmt.Sprint(t["display"], " ", title)
+				continue
+			}
+			part = map[string]any{
+				"slug":     fmt.Sprintf("part-%d", len(parts)+1),
+				"title":    map[string]any{"display": title, "gloss": ""},
+				"chapters": []any{},
+			}
+			parts = append(parts, part)
+		}
+		if chapter["content"] != nil {
+			chapter["content"] = content
+		}
+		if part != nil {
+			part["chapters"] = append(list(part["chapters"]), chapter["slug"])
+		}
+	}
+	if len(parts) > 0 {
+		book["parts"] = parts
+	}
+	return nil
+}
+
+// partMarker reports whether a decoded content item is a part marker, and
+// if so returns its text.
+func partMarker(item any) (string, bool) {
+	m, _ := item.(map[string]any)
+	paragraphs := list(m["paragraph"])
+	if len(paragraphs) != 1 {
+		return "", false
+	}
+	p, _ := paragraphs[0].(map[string]any)
+	if _, ok := p["ref"]; ok || fmt.Sprint(p["verse_id"]) != "0" {
+		return "", false
+	}
+	words := []string{}
+	for _, w := range list(p["words"]) {
+		w, _ := w.(map[string]any)
+		text := ""
+		for _, key := range []string{"pre", "word", "post"} {
+			if s, ok := w[key].(string); ok {
+				text += s
+			}
+		}
+		words = append(words, text)
+	}
+	return strings.Join(words, " "), len(words) > 0
+}
+
+// list returns v as a JSON array, or nil if it is not one.
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
@@ -0,0 +1,165 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// decodeJSON decodes a book file given as JSON the way Migrate sees it.
+func decodeJSON(t *testing.T, s string) map[string]any {
+	t.Helper()
+	decoded, err := decodeRaw(bytes.NewReader([]byte(s)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestMigrateWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]any
+	}{
+		{"λόγος", map[string]any{"word": "λόγος"}},
+		{"«λόγος»,", map[string]any{"word": "λόγος", "pre": "«", "post": "»,"}},
+		{"ἀλλ᾽,", map[string]any{"word": "ἀλλ’", "post": ",", "elision": "᾽"}},
+		{"ἐπ᾿", map[string]any{"word": "ἐπ’", "elision": "᾿"}},
+		{"κατ'", map[string]any{"word": "κατ’", "elision": "'"}},
+		{"δ’", map[string]any{"word": "δ’"}},
+		{"λόγος", map[string]any{"word": "λόγος"}},  // oxia
+		{"λόγος", map[string]any{"word": "λόγος"}}, // decomposed
+		{"ἔφη·", map[string]any{"word": "ἔφη", "post": "·"}},
+		{"τί;", map[string]any{"word": "τί", "post": ";"}},
+		{"FIRST PART", map[string]any{"word": "FIRST PART"}},
+		{"—", map[string]any{"word": "—"}},
+	}
+	for _, tt := range tests {
+		w := map[string]any{"word": tt.in}
+		book := map[string]any{"chapters": []any{map[string]any{"content": []any{map[string]any{"paragraph": []any{map[string]any{"words": []any{w}}}}}}}}
+		if err := migrateWords(book); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(w, tt.want) {
+			t.Errorf("migrateWords(%q) = %q, want %q", tt.in, w, tt.want)
+		}
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	decoded := decodeJSON(t, `{
+		"id": "test", "title": "Test", "slug": "test", "author": "Anonymous", "language": "greek",
+		"description": "", "restricted": false, "coverImage": "",
+		"chapters": [{
+			"slug": "chapter-1", "title": {"display": "Chapter 1", "gloss": ""}, "titleImage": "",
+			"vocab": ["λόγος"], "questions": ["Τίς;"],
+			"content": [
+				{"paragraph": [{"verse_id": 0, "words": [{"word": "FIRST PART", "gloss": ""}]}]},
+				{"subtitle": "", "paragraph": [{"verse_id": 1, "words": [{"word": "«Ἐν", "gloss": "in"}, {"word": "ἀρχῇ»·", "gloss": ""}]}]}
+			]
+		}]
+	}`)
+	applied, err := Migrate(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != SchemaVersion {
+		t.Errorf("applied %d steps, want %d", len(applied), SchemaVersion)
+	}
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	got := &Book{}
+	if err := dec.Decode(got); err != nil {
+		t.Fatalf("migrated book does not fit the format: %v", err)
+	}
+	chapter := got.Chapters[0]
+	if got.SchemaVersion != SchemaVersion || got.Slug != "test" {
+		t.Errorf("schemaVersion %d, slug %q", got.SchemaVersion, got.Slug)
+	}
+	if want := []VocabItem{{Word: "λόγος"}}; !reflect.DeepEqual(chapter.Vocab, want) {
+		t.Errorf("vocab = %+v, want %+v", chapter.Vocab, want)
+	}
+	if want := []Question{{Question: "Τίς;"}}; !reflect.DeepEqual(chapter.Questions, want) {
+		t.Errorf("questions = %+v, want %+v", chapter.Questions, want)
+	}
+	wantParts := []*Part{{Slug: "part-1", Title: Title{Display: "FIRST PART"}, Chapters: []string{"chapter-1"}}}
+	if !reflect.DeepEqual(got.Parts, wantParts) {
+		t.Errorf("parts = %+v, want %+v", got.Parts, wantParts)
+	}
+	wantWords := []Word{{Word: "Ἐν", Gloss: "in", Pre: "«"}, {Word: "ἀρχῇ", Post: "»·"}}
+	if len(chapter.Content) != 1 || !reflect.DeepEqual(chapter.Content[0].Paragraph[0].Words, wantWords) {
+		t.Errorf("content = %+v, want one verse of %+v", chapter.Content, wantWords)
+	}
+}
+
+// TestMigrateFileRoundTrip checks that every word of a migrated file is
+// written as it was before, elision marks included.
+func TestMigrateFileRoundTrip(t *testing.T) {
+	words := []string{"«ἀλλ᾽", "ἐπ᾿", "αὐτῷ,", "κατ'", "ἐμέ,", "δ’", "οὖν·", "τί;»"}
+	list := []map[string]any{}
+	for _, w := range words {
+		list = append(list, map[string]any{"word": w, "gloss": ""})
+	}
+	data, err := json.Marshal(map[string]any{
+		"title": "Test", "slug": "test", "author": "Anonymous", "language": "Greek", "description": "",
+		"chapters": []any{map[string]any{
+			"slug": "chapter-1", "title": map[string]any{"display": "Κεφάλαιον αʹ", "gloss": ""},
+			"content": []any{map[string]any{"paragraph": []any{map[string]any{"verse_id": 1, "words": list}}}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "test.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateFile(path, true); err != nil {
+		t.Fatal(err)
+	}
+	book, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, w := range book.Chapters[0].Content[0].Paragraph[0].Words {
+		got = append(got, w.Written())
+	}
+	if !reflect.DeepEqual(got, words) {
+		t.Errorf("migrated words are written %q, want %q", got, words)
+	}
+	if applied, err := MigrateFile(path, true); err != nil || len(applied) != 0 {
+		t.Errorf("migrating again applied %q, %v", applied, err)
+	}
+}
+
+func TestMigrateRefusesNewer(t *testing.T) {
+	decoded := decodeJSON(t, `{"schemaVersion": 99, "title": "Test"}`)
+	if _, err := Migrate(decoded); err == nil {
+		t.Errorf("Migrate accepted a file from a newer version")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{`{"id": "test"}`, 0},
+		{`{"restricted": true}`, 0},
+		{`{"title": "Test"}`, 1},
+		{`{"schemaVersion": 2}`, 2},
+	}
+	for _, tt := range tests {
+		if got, err := Version(decodeJSON(t, tt.in)); err != nil || got != tt.want {
+			t.Errorf("Version(%s) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package book
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Part is a division of a book above its chapters, such as the "FIRST
+// PART" of the Stoffel epitome or the Visions of the Shepherd of Hermas. A
+// part may itself be divided into parts, as the Stoffel epitome's parts
+// are by its headings. Chapters lists the slugs of the chapters directly
+// in the part, in order.
+//
+// Parts only outline the book: its chapters stay in Book.Chapters, so that
+// an app that knows nothing of parts still has every chapter. Below the
+// chapters, a chapter's sections are the content items that carry a
+// subtitle.
+type Part struct {
+	Slug     string   `json:"slug"`
+	Title    Title    `json:"title"`
+	Parts    []*Part  `json:"parts,omitempty"`
+	Chapters []string `json:"chapters"`
+}
+
+// ManifestPart declares a part of a book whose source does not mark its
+// parts. The part starts at the chapter whose first verse is From, such as
+// "6" for the Mandates of the Shepherd, and runs to the next part.
+type ManifestPart struct {
+	Title string `json:"title"`
+	Gloss string `json:"gloss,omitempty"`
+	From  string `json:"from"`
+}
+
+// newPart returns an empty part numbered n among the parts of the book.
+func newPart(n int, title string) *Part {
+	return &Part{Slug: fmt.Sprintf("part-%d", n), Title: Title{Display: title, Gloss: ""}, Chapters: []string{}}
+}
+
+// countParts returns the number of parts in parts and all their parts.
+func countParts(parts []*Part) int {
+	n := len(parts)
+	for _, p := range parts {
+		n += countParts(p.Parts)
+	}
+	return n
+}
+
+// eachPart calls fn for every part, parents before their parts.
+func eachPart(parts []*Part, fn func(*Part)) {
+	for _, p := range parts {
+		fn(p)
+		eachPart(p.Parts, fn)
+	}
+}
+
+// partOf maps each chapter slug to the part that holds it.
+func partOf(parts []*Part) map[string]*Part {
+	m := map[string]*Part{}
+	eachPart(parts, func(p *Part) {
+		for _, slug := range p.Chapters {
+			m[slug] = p
+		}
+	})
+	return m
+}
+
+// pruneParts drops the parts that hold no chapters, such as a closing
+// "Τὸ τέλος." heading. It returns nil when no part is left, as the parts
+// of a book read back from JSON are, since both are omitted when empty.
+func pruneParts(parts []*Part) []*Part {
+	var kept []*Part
+	for _, p := range parts {
+		p.Parts = pruneParts(p.Parts)
+		if len(p.Parts) > 0 || len(p.Chapters) > 0 {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// applyManifestParts replaces the book's parts with those the manifest
+// declares, placing each chapter in the last part that starts at or before
+// its first verse.
+func applyManifestParts(book *Book, declared []ManifestPart) error {
+	starts := make([]Ref, len(declared))
+	book.Parts = []*Part{}
+	for i, d := range declared {
+		ref, err := ParseRef(d.From)
+		if err != nil {
+			return fmt.Errorf("part %q: %v", d.Title, err)
+		}
+		starts[i] = ref
+		part := newPart(i+1, d.Title)
+		part.Title.Gloss = d.Gloss
+		book.Parts = append(book.Parts, part)
+	}
+	for _, chapter := range book.Chapters {
+		verses := chapterVerses(chapter)
+		var first *Ref
+		for _, p := range verses {
+			if p.Ref != nil {
+				first = p.Ref
+				break
+			}
+		}
+		if first == nil {
+			continue
+		}
+		for i := len(starts) - 1; i >= 0; i-- {
+			if first.Compare(starts[i]) >= 0 {
+				book.Parts[i].Chapters = append(book.Parts[i].Chapters, chapter.Slug)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// checkParts reports parts that name chapters the book does not have, or
+// that name a chapter another part already holds or out of reading order.
+func checkParts(book *Book) []string {
+	problems := []string{}
+	index := map[string]int{}
+	for i, c := range book.Chapters {
+		index[c.Slug] = i
+	}
+	seen := map[string]bool{}
+	last := -1
+	eachPart(book.Parts, func(p *Part) {
+		for _, slug := range p.Chapters {
+			i, ok := index[slug]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("part %s: no chapter %s", p.Slug, slug))
+			case seen[slug]:
+				problems = append(problems, fmt.Sprintf("part %s: chapter %s is already in a part", p.Slug, slug))
+			case i < last:
+				problems = append(problems, fmt.Sprintf("part %s: chapter %s is out of order", p.Slug, slug))
+			}
+			seen[slug] = true
+			last = max(last, i)
+		}
+	})
+	return problems
+}
+
+// joinTitle adds a further line of a heading that the source spreads over
+// several lines.
+func joinTitle(title *Title, l Line) {
+	if l.Lang == "en" {
+		title.Gloss = strings.TrimSpace(title.Gloss + " " + l.Text)
+	} else {
+		title.Display = strings.TrimSpace(title.Display + " " + l.Text)
+	}
+}
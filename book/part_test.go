@@ -0,0 +1,125 @@
+package book
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// describeParts writes each part as its slug, title and chapters, with the
+// parts inside it indented below.
+func describeParts(parts []*Part, indent string) []string {
+	list := []string{}
+	for _, p := range parts {
+		list = append(list, indent+p.Slug+" "+p.Title.Display+"|"+p.Title.Gloss+" "+fmt.Sprint(p.Chapters))
+		list = append(list, describeParts(p.Parts, indent+"  ")...)
+	}
+	return list
+}
+
+const partsSource = `0.0 FIRST PART
+0.0.grc ΜΕΡΟΣ ΠΡΩΤΟΝ
+title.0 BIRTH AND INFANCY
+title.0 OF OUR LORD.
+1.title Mary and Joseph.
+1.1 Ἐν ταῖς ἡμέραις Ἡρώδου
+2.title Zachary and Elizabeth.
+2.1 Ἐν τῷ αὐτῷ χρόνῳ
+title.0 THE HIDDEN LIFE.
+3.title The Return to Nazareth.
+3.1 Καὶ ὑπέστρεψαν εἰς Ναζαρέτ.
+0.0 SECOND PART
+4.title The Baptism.
+4.1 Τότε παραγίνεται ὁ Ἰησοῦς.
+0.0 Τὸ τέλος.
+`
+
+func TestStoffelParts(t *testing.T) {
+	b, _ := convertSource(t, `{"title": "Test", "scheme": "stoffel", "grouping": "titles"}`, partsSource)
+	want := []string{
+		// The Greek and English lines of a part name are one title, as are
+		// the two lines of the first heading. A title given only in
+		// English is displayed in English.
+		"part-1 ΜΕΡΟΣ ΠΡΩΤΟΝ|FIRST PART []",
+		"  part-2 BIRTH AND INFANCY OF OUR LORD.|BIRTH AND INFANCY OF OUR LORD. [chapter-1 chapter-2]",
+		"  part-3 THE HIDDEN LIFE.|THE HIDDEN LIFE. [chapter-3]",
+		// A part with no headings holds its chapters itself, and the
+		// closing "Τὸ τέλος." holds none and is dropped.
+		"part-4 SECOND PART|SECOND PART [chapter-4]",
+	}
+	if got := describeParts(b.Parts, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("parts:\n%q\nwant:\n%q", got, want)
+	}
+	if problems := checkParts(b); len(problems) != 0 {
+		t.Errorf("checkParts = %q", problems)
+	}
+}
+
+func TestPruneParts(t *testing.T) {
+	empty := func() *Part { return newPart(1, "Τὸ τέλος.") }
+	full := func() *Part {
+		p := newPart(2, "")
+		p.Chapters = []string{"chapter-1"}
+		return p
+	}
+	if got := pruneParts([]*Part{empty(), empty()}); got != nil {
+		t.Errorf("pruneParts of empty parts = %+v, want nil", got)
+	}
+	if got := pruneParts(nil); got != nil {
+		t.Errorf("pruneParts(nil) = %+v, want nil", got)
+	}
+	outer := newPart(3, "")
+	outer.Parts = []*Part{empty(), full()}
+	inner := newPart(4, "")
+	inner.Parts = []*Part{empty()}
+	got := pruneParts([]*Part{outer, inner, empty()})
+	if len(got) != 1 || got[0] != outer || len(outer.Parts) != 1 || outer.Parts[0].Slug != "part-2" {
+		t.Errorf("pruneParts kept %q", describeParts(got, ""))
+	}
+}
+
+func TestApplyManifestParts(t *testing.T) {
+	b := &Book{}
+	for i, first := range []Ref{NewRef(1, 1), NewRef(5, 1), NewRef(6, 1), NewRef(12, 1)} {
+		chapter := NewChapter(fmt.Sprintf("chapter-%d", i+1), "")
+		chapter.Content = []ContentItem{{Paragraph: []Paragraph{{Ref: &first}}}}
+		b.Chapters = append(b.Chapters, chapter)
+	}
+	// A chapter with no verses is in no part.
+	b.Chapters = append(b.Chapters, NewChapter("chapter-5", ""))
+	err := applyManifestParts(b, []ManifestPart{
+		{Title: "Ὁράσεις", Gloss: "Visions", From: "1"},
+		{Title: "Ἐντολαί", Gloss: "Mandates", From: "6"},
+		{Title: "Παραβολαί", Gloss: "Similitudes", From: "12.1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"part-1 Ὁράσεις|Visions [chapter-1 chapter-2]",
+		"part-2 Ἐντολαί|Mandates [chapter-3]",
+		"part-3 Παραβολαί|Similitudes [chapter-4]",
+	}
+	if got := describeParts(b.Parts, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("parts = %q, want %q", got, want)
+	}
+	if err := applyManifestParts(b, []ManifestPart{{Title: "Ὁράσεις", From: "one"}}); err == nil {
+		t.Error("applyManifestParts accepted a part starting at a ref that is not a number")
+	}
+}
+
+func TestCheckParts(t *testing.T) {
+	b := &Book{Chapters: []*Chapter{NewChapter("chapter-1", ""), NewChapter("chapter-2", ""), NewChapter("chapter-3", "")}}
+	first, second := newPart(1, ""), newPart(2, "")
+	first.Chapters = []string{"chapter-1", "chapter-3", "chapter-4"}
+	second.Chapters = []string{"chapter-2", "chapter-3"}
+	b.Parts = []*Part{first, second}
+	want := []string{
+		"part part-1: no chapter chapter-4",
+		"part part-2: chapter chapter-2 is out of order",
+		"part part-2: chapter chapter-3 is already in a part",
+	}
+	if got := checkParts(b); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkParts = %q, want %q", got, want)
+	}
+}
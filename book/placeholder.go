@@ -0,0 +1,115 @@
+package book
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+)
+
+// palette is the colour scheme of the placeholder covers of a collection.
+type palette struct {
+	background, accent, text string
+}
+
+// collectionPalettes gives each collection of the library its own colours.
+// Other collections are given one of fallbackPalettes by the hash of their
+// name.
+var collectionPalettes = map[string]palette{
+	"Plato":                 {background: "#1f3a5f", accent: "#c9a227", text: "#f5efe0"},
+	"Apostolic Fathers":     {background: "#5a1e1e", accent: "#d4af37", text: "#f7f0e1"},
+	"Letters of Ignatius":   {background: "#6b2a1a", accent: "#d4af37", text: "#f7f0e1"},
+	"Clement of Alexandria": {background: "#23473b", accent: "#c7b27c", text: "#f2eee3"},
+	"Justin Martyr":         {background: "#3d2b56", accent: "#cfa85a", text: "#f4eefa"},
+	"Stoffel":               {background: "#4a3b2a", accent: "#e0b96a", text: "#faf3e6"},
+}
+
+var fallbackPalettes = []palette{
+	{background: "#2f4858", accent: "#f6ae2d", text: "#f4f4f4"},
+	{background: "#33658a", accent: "#f26419", text: "#f4f4f4"},
+	{background: "#55505c", accent: "#d1b490", text: "#f4f1ea"},
+}
+
+func collectionPalette(collection string) palette {
+	if p, ok := collectionPalettes[collection]; ok {
+		return p
+	}
+	h := fnv.New32a()
+	h.Write([]byte(collection))
+	return fallbackPalettes[h.Sum32()%uint32(len(fallbackPalettes))]
+}
+
+// PlaceholderPath returns the path the placeholder cover of a book is
+// written to: the book file with an .svg extension.
+func (m *Manifest) PlaceholderPath() string {
+	return strings.TrimSuffix(m.OutputPath(), ".json") + ".svg"
+}
+
+// writePlaceholder writes an SVG cover for a book that has none, showing
+// its Greek and English titles, author and shelf, as Manifest.Shelf gives
+// it, in the shelf's colours. It uses only the generic serif and
+// sans-serif font families, so that it needs no fonts of its own.
+func writePlaceholder(path string, m *Manifest, shelf string) error {
+	p := collectionPalette(shelf)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="600" height="900" viewBox="0 0 600 900">`+"\n")
+	fmt.Fprintf(&b, `  <rect width="600" height="900" fill="%s"/>`+"\n", p.background)
+	fmt.Fprintf(&b, `  <rect x="30" y="30" width="540" height="840" fill="none" stroke="%s" stroke-width="4"/>`+"\n", p.accent)
+	fmt.Fprintf(&b, `  <rect x="42" y="42" width="516" height="816" fill="none" stroke="%s" stroke-width="1"/>`+"\n", p.accent)
+	text := func(y, size int, family, fill, s string) {
+		fmt.Fprintf(&b, `  <text x="300" y="%d" font-family="%s" font-size="%d" fill="%s" text-anchor="middle">%s</text>`+"\n",
+			y, family, size, fill, escapeXML(s))
+	}
+	if shelf != "" && shelf != m.Author {
+		text(110, 24, "sans-serif", p.accent, strings.ToUpper(shelf))
+	}
+	y := 330
+	if m.GreekTitle != "" {
+		lines := wrap(m.GreekTitle, 16)
+		y -= 35 * (len(lines) - 1)
+		for _, line := range lines {
+			text(y, 60, "serif", p.text, line)
+			y += 70
+		}
+		fmt.Fprintf(&b, `  <line x1="200" y1="%d" x2="400" y2="%d" stroke="%s" stroke-width="2"/>`+"\n", y-20, y-20, p.accent)
+		y += 40
+	}
+	for _, line := range wrap(m.Title, 24) {
+		text(y, 40, "serif", p.text, line)
+		y += 50
+	}
+	if m.Author != "" {
+		text(800, 30, "sans-serif", p.accent, m.Author)
+	}
+	b.WriteString("</svg>\n")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error writing placeholder cover: %v", err)
+	}
+	return nil
+}
+
+// wrap breaks s into lines of at most width characters where it can.
+func wrap(s string, width int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func escapeXML(s string) string {
+	return xmlEscaper.Replace(s)
+}
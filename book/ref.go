@@ -0,0 +1,97 @@
+package book
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Ref is a hierarchical reference to a verse, such as 10.2 for chapter 10
+// verse 2 or 1.1.3.1 in the Paidagogos. Cite holds the canonical form of
+// the reference, without the zero padding some sources use, so a Ref can be
+// looked up and cited from the JSON alone.
+type Ref struct {
+	Levels []int  `json:"levels"`
+	Cite   string `json:"cite"`
+}
+
+// NewRef returns the reference with the given levels.
+func NewRef(levels ...int) Ref {
+	parts := make([]string, len(levels))
+	for i, n := range levels {
+		parts[i] = strconv.Itoa(n)
+	}
+	return Ref{Levels: levels, Cite: strings.Join(parts, ".")}
+}
+
+// ParseRef parses a dotted reference such as "10.2" or "001.01.02".
+func ParseRef(s string) (Ref, error) {
+	parts := strings.Split(s, ".")
+	levels := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Ref{}, fmt.Errorf("invalid reference %q", s)
+		}
+		levels[i] = n
+	}
+	return NewRef(levels...), nil
+}
+
+func (r Ref) String() string {
+	return r.Cite
+}
+
+// IsZero reports whether r has no levels.
+func (r Ref) IsZero() bool {
+	return len(r.Levels) == 0
+}
+
+// Compare orders references level by level, a shorter reference sorting
+// before the longer ones it is a prefix of. It returns -1, 0 or +1.
+func (r Ref) Compare(other Ref) int {
+	for i := 0; i < len(r.Levels) && i < len(other.Levels); i++ {
+		if r.Levels[i] != other.Levels[i] {
+			if r.Levels[i] < other.Levels[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(r.Levels) < len(other.Levels):
+		return -1
+	case len(r.Levels) > len(other.Levels):
+		return 1
+	}
+	return 0
+}
+
+// HasPrefix reports whether r lies within prefix, so that 1.1.3.1 lies
+// within 1.1.
+func (r Ref) HasPrefix(prefix Ref) bool {
+	if len(prefix.Levels) > len(r.Levels) {
+		return false
+	}
+	for i, n := range prefix.Levels {
+		if r.Levels[i] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// Lookup returns the verse cited by ref, or nil if the book has none.
+func (b *Book) Lookup(ref Ref) *Paragraph {
+	for _, chapter := range b.Chapters {
+		for i := range chapter.Content {
+			for j := range chapter.Content[i].Paragraph {
+				p := &chapter.Content[i].Paragraph[j]
+				if p.Ref != nil && p.Ref.Compare(ref) == 0 {
+					return p
+				}
+			}
+		}
+	}
+	return nil
+}
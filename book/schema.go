@@ -0,0 +1,71 @@
+package book
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaName is the file the JSON Schema of the book format is published
+// as, at the root of the repository.
+const SchemaName = "book.schema.json"
+
+// Schema returns the JSON Schema of the book format. It is generated from
+// the Go types, so it cannot drift from what Convert writes: every field
+// without omitempty is required and no other fields are allowed.
+func Schema() map[string]any {
+	defs := map[string]any{}
+	root := schemaFor(reflect.TypeOf(Book{}), defs)
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "Graded reader book",
+		"$ref":    root["$ref"],
+		"$defs":   defs,
+	}
+}
+
+func schemaFor(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), defs)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), defs)}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := defs[name]; !ok {
+			defs[name] = nil // stops recursion through the type's own fields
+			properties := map[string]any{}
+			required := []string{}
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				tag := f.Tag.Get("json")
+				if !f.IsExported() || tag == "-" {
+					continue
+				}
+				key, opts, _ := strings.Cut(tag, ",")
+				if key == "" {
+					key = f.Name
+				}
+				properties[key] = schemaFor(f.Type, defs)
+				if opts != "omitempty" {
+					required = append(required, key)
+				}
+			}
+			defs[name] = map[string]any{
+				"type":                 "object",
+				"properties":           properties,
+				"required":             required,
+				"additionalProperties": false,
+			}
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	}
+	panic("book: no schema for " + t.String())
+}
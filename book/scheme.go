@@ -0,0 +1,262 @@
+package book
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LineKind says what a source line contributes to the book.
+type LineKind int
+
+const (
+	// VerseLine carries the text of one verse.
+	VerseLine LineKind = iota
+	// TitleLine titles the chapter that follows it.
+	TitleLine
+	// PartLine names a part of the book, such as Stoffel's "FIRST PART".
+	PartLine
+	// HeadingLine is a heading inside a part that does not start a chapter.
+	HeadingLine
+)
+
+// Line is one source line split into its reference and text. Lang is
+// "en" for a title, part or heading given in English, which becomes the
+// gloss of the title, and "" for one in Greek, which becomes its display
+// title. A title the source gives only in English is displayed in English
+// until a Greek one is added; see fillTitles.
+type Line struct {
+	Kind LineKind
+	Ref  Ref
+	Text string
+	Lang string
+}
+
+// setTitle sets the display title or gloss of t from a title, part or
+// heading line, by its language.
+func (l Line) setTitle(t *Title) {
+	if l.Lang == "en" {
+		t.Gloss = l.Text
+	} else {
+		t.Display = l.Text
+	}
+}
+
+// fillTitles gives the chapters and parts whose source has only an English
+// title that title as their display title too, so that every title has
+// something to show until the source gives it in Greek.
+func fillTitles(book *Book) {
+	for _, c := range book.Chapters {
+		if c.Title.Display == "" {
+			c.Title.Display = c.Title.Gloss
+		}
+	}
+	eachPart(book.Parts, func(p *Part) {
+		if p.Title.Display == "" {
+			p.Title.Display = p.Title.Gloss
+		}
+	})
+}
+
+// A RefScheme recognises the reference at the start of a source line.
+// Parse reports false for lines that do not belong to the scheme.
+type RefScheme interface {
+	Name() string
+	Parse(line string) (Line, bool)
+}
+
+// schemes lists every known scheme, from the most general to the most
+// specific; DetectScheme prefers earlier entries when scores tie.
+var schemes = []RefScheme{
+	dottedScheme{name: "n.m", pattern: regexp.MustCompile(`^([0-9]+\.[0-9]+)\s+(.*)$`)},
+	dottedScheme{name: "nnn.nn.nn", pattern: regexp.MustCompile(`^([0-9]{3}\.[0-9]{2}\.[0-9]{2})\s+(.*)$`)},
+	dottedScheme{name: "n.m.k", pattern: regexp.MustCompile(`^([0-9]+\.[0-9]+\.[0-9]+)\s+(.*)$`)},
+	bracketScheme{},
+	shepherdScheme{},
+	stoffelScheme{},
+}
+
+// LookupScheme returns the scheme with the given name.
+func LookupScheme(name string) (RefScheme, error) {
+	if name == "lines" {
+		return &lineScheme{}, nil
+	}
+	for _, s := range schemes {
+		if s.Name() == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown reference scheme %q", name)
+}
+
+// sampleSize is the number of non-empty lines DetectScheme looks at.
+const sampleSize = 200
+
+// DetectScheme picks the scheme that recognises the most lines in a sample
+// taken from the start of the text. A line a scheme reads as a verse
+// scores 2 and one it reads as a title, part or heading scores 3, so that
+// a scheme with headings wins over a plain one that reads the same lines
+// as verses. Text that no scheme recognises is read one verse per line.
+func DetectScheme(lines []string) RefScheme {
+	sample := []string{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sample = append(sample, line)
+		if len(sample) == sampleSize {
+			break
+		}
+	}
+
+	var best RefScheme
+	bestScore := 0
+	for _, s := range schemes {
+		score := 0
+		for _, line := range sample {
+			l, ok := s.Parse(line)
+			if !ok {
+				continue
+			}
+			score += 2
+			if l.Kind != VerseLine {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	if best == nil {
+		return &lineScheme{}
+	}
+	return best
+}
+
+// dottedScheme reads verses numbered by a fixed number of dotted levels,
+// such as "10.2" or "001.01.02".
+type dottedScheme struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+func (s dottedScheme) Name() string { return s.name }
+
+func (s dottedScheme) Parse(line string) (Line, bool) {
+	matches := s.pattern.FindStringSubmatch(line)
+	if matches == nil {
+		return Line{}, false
+	}
+	ref, err := ParseRef(matches[1])
+	if err != nil {
+		return Line{}, false
+	}
+	return Line{Kind: VerseLine, Ref: ref, Text: matches[2]}, true
+}
+
+// bracketScheme reads the Paidagogos references, "[{1.1.1.1}]" for verses
+// and "[{1.1.t}]" for the title of chapter 1.1.
+type bracketScheme struct{}
+
+var bracketRegex = regexp.MustCompile(`^\[\{([0-9]+(?:\.[0-9]+)*)(\.t)?\}\]\s*(.*)$`)
+
+func (bracketScheme) Name() string { return "bracketed" }
+
+func (bracketScheme) Parse(line string) (Line, bool) {
+	matches := bracketRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return Line{}, false
+	}
+	ref, err := ParseRef(matches[1])
+	if err != nil {
+		return Line{}, false
+	}
+	kind := VerseLine
+	if matches[2] != "" {
+		kind = TitleLine
+	}
+	return Line{Kind: kind, Ref: ref, Text: matches[3]}, true
+}
+
+// shepherdScheme reads the Shepherd of Hermas, whose "N.M.K" verses are
+// grouped under "N.0.0" headings such as "1.0.0 Ὅρασις αʹ".
+type shepherdScheme struct{}
+
+var shepherdRegex = regexp.MustCompile(`^([0-9]+\.[0-9]+\.[0-9]+)\s+(.*)$`)
+
+func (shepherdScheme) Name() string { return "shepherd" }
+
+func (shepherdScheme) Parse(line string) (Line, bool) {
+	matches := shepherdRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return Line{}, false
+	}
+	ref, err := ParseRef(matches[1])
+	if err != nil {
+		return Line{}, false
+	}
+	if ref.Levels[1] == 0 && ref.Levels[2] == 0 {
+		return Line{Kind: TitleLine, Ref: NewRef(ref.Levels[0]), Text: matches[2]}, true
+	}
+	return Line{Kind: VerseLine, Ref: ref, Text: matches[2]}, true
+}
+
+// stoffelScheme reads the Stoffel epitome: "N.M" verses, "N.title" chapter
+// titles, "0.0" part names and "title.0" headings within a part. A title,
+// part or heading may be marked ".grc" for Greek or ".en" for English, as
+// in "1.title.grc" and "1.title.en", to give a chapter both; unmarked ones
+// are English, as the epitome's own are.
+type stoffelScheme struct{}
+
+var (
+	stoffelVerse   = regexp.MustCompile(`^([0-9]+\.[0-9]+)\s+(.*)$`)
+	stoffelTitle   = regexp.MustCompile(`^([0-9]+)\.title(?:\.(grc|en))?\s+(.+)$`)
+	stoffelPart    = regexp.MustCompile(`^0\.0(?:\.(grc|en))?\s+(.+)$`)
+	stoffelHeading = regexp.MustCompile(`^title\.0(?:\.(grc|en))?\s+(.+)$`)
+)
+
+// stoffelLang returns the Lang of a line marked with lang.
+func stoffelLang(lang string) string {
+	if lang == "grc" {
+		return ""
+	}
+	return "en"
+}
+
+func (stoffelScheme) Name() string { return "stoffel" }
+
+func (stoffelScheme) Parse(line string) (Line, bool) {
+	if matches := stoffelPart.FindStringSubmatch(line); matches != nil {
+		return Line{Kind: PartLine, Text: matches[2], Lang: stoffelLang(matches[1])}, true
+	}
+	if matches := stoffelHeading.FindStringSubmatch(line); matches != nil {
+		return Line{Kind: HeadingLine, Text: matches[2], Lang: stoffelLang(matches[1])}, true
+	}
+	if matches := stoffelTitle.FindStringSubmatch(line); matches != nil {
+		n, _ := strconv.Atoi(matches[1])
+		return Line{Kind: TitleLine, Ref: NewRef(n), Text: matches[3], Lang: stoffelLang(matches[2])}, true
+	}
+	if matches := stoffelVerse.FindStringSubmatch(line); matches != nil {
+		ref, err := ParseRef(matches[1])
+		if err != nil {
+			return Line{}, false
+		}
+		return Line{Kind: VerseLine, Ref: ref, Text: matches[2]}, true
+	}
+	return Line{}, false
+}
+
+// lineScheme reads unnumbered text, one verse per line. It counts the lines
+// it has seen, so each conversion needs a fresh value.
+type lineScheme struct {
+	count int
+}
+
+func (*lineScheme) Name() string { return "lines" }
+
+func (s *lineScheme) Parse(line string) (Line, bool) {
+	s.count++
+	return Line{Kind: VerseLine, Ref: NewRef(s.count), Text: line}, true
+}
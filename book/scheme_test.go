@@ -0,0 +1,91 @@
+package book
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+func TestDetectScheme(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{[]string{"1.1 ἐν ἀρχῇ", "1.2 ἦν ὁ λόγος"}, "n.m"},
+		{[]string{"017.01.01 ἐν ἀρχῇ", "017.01.02 ἦν ὁ λόγος"}, "nnn.nn.nn"},
+		{[]string{"1.1.1 ἐν ἀρχῇ", "1.1.2 ἦν ὁ λόγος"}, "n.m.k"},
+		{[]string{"", "  ", "ἐν ἀρχῇ"}, "lines"},
+		{nil, "lines"},
+	}
+	for _, tt := range tests {
+		if got := DetectScheme(tt.lines).Name(); got != tt.want {
+			t.Errorf("DetectScheme(%q) = %s, want %s", tt.lines, got, tt.want)
+		}
+	}
+}
+
+// TestDetectSchemeSources checks the scheme detected for the source of
+// every book in the repository that does not name its own.
+func TestDetectSchemeSources(t *testing.T) {
+	want := map[string]string{
+		"ApostolicFathers/Clement/i_clement":       "n.m",
+		"ApostolicFathers/Clement/ii_clement":      "n.m",
+		"ApostolicFathers/Ignatius/ephesians":      "n.m",
+		"ApostolicFathers/Ignatius/magnesians":     "n.m",
+		"ApostolicFathers/Ignatius/philadelphians": "n.m",
+		"ApostolicFathers/Ignatius/polycarp":       "n.m",
+		"ApostolicFathers/Ignatius/romans":         "n.m",
+		"ApostolicFathers/Ignatius/smyrnaeans":     "n.m",
+		"ApostolicFathers/Ignatius/trallians":      "n.m",
+		"ApostolicFathers/Polycarp/philippians":    "n.m",
+		"ApostolicFathers/barnabas":                "n.m",
+		"ApostolicFathers/didache":                 "n.m",
+		"ApostolicFathers/diognetus":               "n.m",
+		"ApostolicFathers/martyrdom":               "n.m",
+		"ApostolicFathers/shepherd":                "shepherd",
+		"ClementOfAlexandria/paidagogos-gk-bk-1":   "bracketed",
+		"ClementOfAlexandria/paidagogos-gk-bk-2":   "bracketed",
+		"ClementOfAlexandria/paidagogos-gk-bk-3":   "bracketed",
+		"JustinMartyr/1-apology":                   "n.m",
+		"Plato/Republic":                           "n.m.k",
+		"Plato/apology":                            "nnn.nn.nn",
+		"Plato/crito":                              "n.m",
+		"Plato/euthyphro":                          "n.m",
+		"Plato/ion":                                "n.m",
+		"Plato/meno":                               "n.m",
+		"Plato/phaedo":                             "n.m",
+		"Plato/symposium":                          "nnn.nn.nn",
+		"StoffleEpitome":                           "stoffel",
+	}
+	manifests, err := FindManifests("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range manifests {
+		if len(m.Volumes) > 0 || m.Format != "" {
+			continue
+		}
+		dir, err := filepath.Rel("..", m.Dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir = filepath.ToSlash(dir)
+		content, err := os.ReadFile(m.SourcePath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := DetectScheme(strings.Split(greek.Normalize(string(content)), "\n")).Name()
+		if w, ok := want[dir]; !ok {
+			t.Errorf("%s: detected %s, but the book is not in the table", dir, got)
+		} else if got != w {
+			t.Errorf("%s: detected %s, want %s", dir, got, w)
+		}
+		delete(want, dir)
+	}
+	for dir := range want {
+		t.Errorf("%s: no such book", dir)
+	}
+}
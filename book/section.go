@@ -0,0 +1,258 @@
+package book
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// The sectioned format is for graded chapters written by hand rather than
+// converted from a numbered text. A manifest selects it with
+// "format": "sections". The file is a run of sections, each opened by a
+// header in brackets:
+//
+//	[Chapter]
+//	Title: Ὁ Σωκράτης
+//	Gloss: Socrates
+//	TitleImage: socrates.png
+//
+//	[Vocab]
+//	φιλόσοφος | philosopher | philosopher.png
+//	ἀγορά | marketplace
+//
+//	[Questions]
+//	τίς ἐστιν ὁ Σωκράτης; | φιλόσοφος ἐστιν.
+//
+//	[Content]
+//	Subtitle: Ἐν τῇ ἀγορᾷ
+//	Image: agora.png
+//	ὁ Σωκράτης φιλόσοφος(philosopher) ἐστιν. ἐν τῇ ἀγορᾷ(marketplace) λέγει.
+//
+// [Chapter] starts a new chapter and the other sections add to the chapter
+// above them. Vocab lines are word|gloss with an optional |image, and
+// question lines question|answer. In [Content] each line is a paragraph,
+// with one verse for each sentence; a gloss in parentheses straight after
+// a word glosses that word. Subtitle: and Image: apply to the paragraph
+// that follows them. Verses are cited as chapter.verse.
+
+var sectionHeader = regexp.MustCompile(`^\[([A-Za-z]+)\]$`)
+
+// sectionParser reads the lines of a sectioned file into a book.
+type sectionParser struct {
+	book    *Book
+	diags   *diagnostics
+	section string
+	// skip is set after a bad header, so that the lines of the section
+	// are not each reported as well.
+	skip bool
+
+	chapter *Chapter
+	verse   int // verses of the chapter so far
+
+	// header is the line of the current section's header, and lines the
+	// number of lines in it.
+	header int
+	lines  int
+
+	pendingSubtitle string
+	pendingImage    string
+}
+
+// parseSections reads a file in the sectioned format into book, reporting
+// every malformed line with its file and line number.
+func parseSections(book *Book, lines []string, diags *diagnostics) error {
+	p := &sectionParser{book: book, diags: diags}
+	errs := []error{}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := p.line(line, i+1); err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %v", diags.path, i+1, err))
+		}
+	}
+	p.endSection()
+	return errors.Join(errs...)
+}
+
+func (p *sectionParser) line(line string, lineNo int) error {
+	if matches := sectionHeader.FindStringSubmatch(line); matches != nil {
+		return p.startSection(matches[1], lineNo)
+	}
+	if p.skip {
+		return nil
+	}
+	p.lines++
+	switch p.section {
+	case "Chapter":
+		key, value, ok := directive(line)
+		switch {
+		case ok && key == "Title":
+			p.chapter.Title.Display = value
+		case ok && key == "Gloss":
+			p.chapter.Title.Gloss = value
+		case ok && key == "TitleImage":
+			p.chapter.TitleImage = value
+		default:
+			return fmt.Errorf("expected Title:, Gloss: or TitleImage: in [Chapter], found %q", line)
+		}
+	case "Vocab":
+		parts := splitFields(line)
+		if (len(parts) != 2 && len(parts) != 3) || parts[0] == "" {
+			return fmt.Errorf("vocab line should be word|gloss or word|gloss|image, found %q", line)
+		}
+		item := VocabItem{Word: parts[0], Gloss: parts[1], Image: ""}
+		if len(parts) == 3 {
+			item.Image = parts[2]
+		}
+		p.chapter.Vocab = append(p.chapter.Vocab, item)
+	case "Questions":
+		parts := splitFields(line)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("question line should be question|answer, found %q", line)
+		}
+		p.chapter.Questions = append(p.chapter.Questions, Question{Question: parts[0], Answer: parts[1]})
+	case "Content":
+		if key, value, ok := directive(line); ok && key == "Subtitle" {
+			p.pendingSubtitle = value
+			return nil
+		} else if ok && key == "Image" {
+			p.pendingImage = value
+			return nil
+		}
+		return p.paragraph(line)
+	default:
+		return fmt.Errorf("text before the first section header")
+	}
+	return nil
+}
+
+func (p *sectionParser) startSection(name string, lineNo int) error {
+	p.endSection()
+	p.section, p.header, p.lines, p.skip = name, lineNo, 0, false
+	switch name {
+	case "Chapter":
+		p.chapter = NewChapter(fmt.Sprintf("chapter-%d", len(p.book.Chapters)+1), "")
+		p.book.Chapters = append(p.book.Chapters, p.chapter)
+		p.verse = 0
+	case "Vocab", "Questions", "Content":
+		if p.chapter == nil {
+			p.skip = true
+			return fmt.Errorf("[%s] section before any [Chapter]", name)
+		}
+	default:
+		p.skip = true
+		return fmt.Errorf("unknown section [%s]", name)
+	}
+	return nil
+}
+
+// endSection warns about a section that is left empty or that ends with a
+// Subtitle: or Image: that no paragraph follows.
+func (p *sectionParser) endSection() {
+	if p.section != "" && !p.skip && p.lines == 0 {
+		p.diags.warn(p.header, "empty [%s] section", p.section)
+	}
+	if p.pendingSubtitle != "" || p.pendingImage != "" {
+		p.diags.warn(p.header, "Subtitle: or Image: with no paragraph after it")
+	}
+	p.pendingSubtitle, p.pendingImage = "", ""
+}
+
+// paragraph adds a line of [Content] to the chapter, one verse per
+// sentence.
+func (p *sectionParser) paragraph(line string) error {
+	words, err := glossedWords(line)
+	if err != nil {
+		return err
+	}
+	item := ContentItem{
+		Subtitle:  p.pendingSubtitle,
+		Image:     p.pendingImage,
+		Paragraph: []Paragraph{},
+	}
+	chapter := len(p.book.Chapters)
+	for _, sentence := range sentences(words) {
+		p.verse++
+		ref := NewRef(chapter, p.verse)
+		item.Paragraph = append(item.Paragraph, Paragraph{Ref: &ref, Words: sentence})
+	}
+	if len(item.Paragraph) == 0 {
+		return nil
+	}
+	p.chapter.Content = append(p.chapter.Content, item)
+	p.pendingSubtitle, p.pendingImage = "", ""
+	return nil
+}
+
+var glossRegex = regexp.MustCompile(`\(([^()]*)\)`)
+
+// glossedWords splits a line of [Content] into words, taking a gloss in
+// parentheses straight after a word as that word's gloss.
+func glossedWords(text string) ([]Word, error) {
+	words := &wordList{}
+	word := func(t greek.Token) Word {
+		return Word{Word: t.Form, Gloss: "", Pre: t.Pre, Post: t.Post, Elision: t.Elision}
+	}
+	pos := 0
+	for _, loc := range glossRegex.FindAllStringSubmatchIndex(text, -1) {
+		before := text[pos:loc[0]]
+		if strings.ContainsAny(before, "()") {
+			return nil, fmt.Errorf("unmatched parenthesis in %q", before)
+		}
+		gloss := text[loc[2]:loc[3]]
+		if strings.TrimSpace(before) == "" {
+			return nil, fmt.Errorf("gloss (%s) does not follow a word", gloss)
+		}
+		words.add(before, word)
+		if len(words.words) == 0 {
+			return nil, fmt.Errorf("gloss (%s) does not follow a word", gloss)
+		}
+		words.words[len(words.words)-1].Gloss = strings.TrimSpace(gloss)
+		pos = loc[1]
+	}
+	if strings.ContainsAny(text[pos:], "()") {
+		return nil, fmt.Errorf("unmatched parenthesis in %q", text[pos:])
+	}
+	words.add(text[pos:], word)
+	return words.done(), nil
+}
+
+// sentences splits words into sentences, each ending at a word followed by
+// a full stop, question mark, exclamation mark or ano teleia.
+func sentences(words []Word) [][]Word {
+	result := [][]Word{}
+	start := 0
+	for i, w := range words {
+		if strings.ContainsAny(w.Post, ".;!?·") {
+			result = append(result, words[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(words) {
+		result = append(result, words[start:])
+	}
+	return result
+}
+
+// directive splits a "Key: value" line.
+func directive(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
+
+// splitFields splits a "|" separated line, trimming each field.
+func splitFields(line string) []string {
+	parts := strings.Split(line, "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
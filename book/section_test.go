@@ -0,0 +1,107 @@
+package book
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sectionsManifest returns the manifest of a sectioned book read from a
+// file in testdata.
+func sectionsManifest(source string) *Manifest {
+	return &Manifest{Title: "Test", Slug: "test", Language: "Greek", Format: "sections", Source: source, Output: "test.json", Dir: "testdata"}
+}
+
+func TestSections(t *testing.T) {
+	b, diags, err := Convert(sectionsManifest("sections.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("unexpected warnings %q", messages(diags))
+	}
+	if len(b.Chapters) != 2 {
+		t.Fatalf("got %d chapters, want 2", len(b.Chapters))
+	}
+	first, second := b.Chapters[0], b.Chapters[1]
+	if want := (Title{Display: "Ὁ Σωκράτης", Gloss: "Socrates"}); first.Slug != "chapter-1" || first.Title != want || first.TitleImage != "socrates.png" {
+		t.Errorf("first chapter %s titled %+v with image %q", first.Slug, first.Title, first.TitleImage)
+	}
+	wantVocab := []VocabItem{
+		{Word: "φιλόσοφος", Gloss: "philosopher", Image: "philosopher.png"},
+		{Word: "ἀγορά", Gloss: "marketplace"},
+	}
+	if !reflect.DeepEqual(first.Vocab, wantVocab) {
+		t.Errorf("vocab = %+v, want %+v", first.Vocab, wantVocab)
+	}
+	if want := []Question{{Question: "τίς ἐστιν ὁ Σωκράτης;", Answer: "φιλόσοφος ἐστιν."}}; !reflect.DeepEqual(first.Questions, want) {
+		t.Errorf("questions = %+v, want %+v", first.Questions, want)
+	}
+
+	// Each line of [Content] is a content item with a verse per sentence.
+	if len(first.Content) != 2 {
+		t.Fatalf("got %d content items, want 2", len(first.Content))
+	}
+	item := first.Content[0]
+	if item.Subtitle != "Ἐν τῇ ἀγορᾷ" || item.Image != "agora.png" || first.Content[1].Subtitle != "" || first.Content[1].Image != "" {
+		t.Errorf("Subtitle: and Image: apply to %+v and %+v", item, first.Content[1])
+	}
+	verses := []string{}
+	for _, item := range append(first.Content, second.Content...) {
+		for _, p := range item.Paragraph {
+			words := []string{}
+			for _, w := range p.Words {
+				s := w.Written()
+				if w.Gloss != "" {
+					s += "(" + w.Gloss + ")"
+				}
+				words = append(words, s)
+			}
+			verses = append(verses, p.Ref.String()+" "+strings.Join(words, " "))
+		}
+	}
+	want := []string{
+		"1.1 ὁ Σωκράτης φιλόσοφος(philosopher) ἐστιν.",
+		"1.2 ἐν τῇ ἀγορᾷ(marketplace) λέγει.",
+		"1.3 «τί λέγεις;»(you say)",
+		"1.4 ἔφη.",
+		"2.1 ὁ Κρίτων φίλος(friend) ἐστίν",
+	}
+	if !reflect.DeepEqual(verses, want) {
+		t.Errorf("verses:\n%s\nwant:\n%s", strings.Join(verses, "\n"), strings.Join(want, "\n"))
+	}
+	if p := second.Content[0].Paragraph[0]; p.VerseID != 5 {
+		t.Errorf("last verse numbered %d, want 5", p.VerseID)
+	}
+}
+
+func TestSectionsErrors(t *testing.T) {
+	_, _, err := Convert(sectionsManifest("sections-bad.txt"))
+	if err == nil {
+		t.Fatal("no error")
+	}
+	want := []string{
+		`testdata/sections-bad.txt:1: text before the first section header`,
+		`testdata/sections-bad.txt:2: [Vocab] section before any [Chapter]`,
+		`testdata/sections-bad.txt:6: expected Title:, Gloss: or TitleImage: in [Chapter], found "Author: Plato"`,
+		`testdata/sections-bad.txt:8: vocab line should be word|gloss or word|gloss|image, found "φιλόσοφος"`,
+		`testdata/sections-bad.txt:10: question line should be question|answer, found "τίς ἐστιν; | φιλόσοφος | ναί"`,
+		`testdata/sections-bad.txt:12: gloss (philosopher) does not follow a word`,
+		`testdata/sections-bad.txt:13: unmatched parenthesis in "ὁ Σωκράτης φιλόσοφος(philosopher ἐστιν."`,
+		`testdata/sections-bad.txt:14: unknown section [Glossary]`,
+	}
+	if got := strings.Split(err.Error(), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSectionsWarnings(t *testing.T) {
+	_, diags := convertSource(t, `{"title": "Test", "format": "sections"}`, "[Chapter]\nTitle: Α\n[Vocab]\n[Content]\nSubtitle: Β\n")
+	want := []string{
+		"test.txt:3: empty [Vocab] section",
+		"test.txt:4: Subtitle: or Image: with no paragraph after it",
+	}
+	if got := messages(diags); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package book
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IndexName is the file in a shard directory that lists its chapters.
+const IndexName = "index.json"
+
+// ShardIndex is the index of a sharded book. A sharded book is written as
+// a directory holding an index and one file for each chapter, so that the
+// app can show the first chapter without downloading the whole book and
+// can cache each chapter by its hash. The index holds the book's metadata,
+// with the chapter list in place of the chapters themselves, and each
+// chapter file is a Chapter.
+type ShardIndex struct {
+	*Book
+	Chapters []ChapterShard `json:"chapters"`
+}
+
+// ChapterShard describes one chapter file of a sharded book. Path is
+// relative to the index, Size is the length of the file in bytes and Hash
+// its SHA-256 in hex.
+type ChapterShard struct {
+	Slug   string `json:"slug"`
+	Title  Title  `json:"title"`
+	Path   string `json:"path"`
+	Size   int    `json:"size"`
+	Hash   string `json:"hash"`
+	Verses int    `json:"verses"`
+}
+
+// ShardDir returns the directory a book written to path is sharded into:
+// the path without its .json extension.
+func ShardDir(path string) string {
+	return strings.TrimSuffix(path, ".json")
+}
+
+// WriteShards writes the book to dir as an index and a file per chapter,
+// replacing any shards already there. Files are indented unless the
+// encoding is compact, in which case empty glosses are left out but the
+// gloss table is not used, so that each chapter file stands alone. With
+// Gzip each file also gets a compressed copy.
+func WriteShards(dir string, book *Book, enc Encoding) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error writing shards: %v", err)
+	}
+	old, _ := filepath.Glob(filepath.Join(dir, "*.json*"))
+	for _, path := range old {
+		os.Remove(path)
+	}
+	index := ShardIndex{Book: book, Chapters: []ChapterShard{}}
+	for _, chapter := range book.Chapters {
+		var data []byte
+		var err error
+		if enc.Compact {
+			data, err = encodeCompact(chapter, nil)
+		} else {
+			data, err = json.MarshalIndent(chapter, "", "  ")
+			data = joinLevels(data)
+		}
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON: %v", err)
+		}
+		name := chapter.Slug + ".json"
+		if err := writeShard(filepath.Join(dir, name), data, enc.Gzip); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		index.Chapters = append(index.Chapters, ChapterShard{
+			Slug:   chapter.Slug,
+			Title:  chapter.Title,
+			Path:   name,
+			Size:   len(data),
+			Hash:   hex.EncodeToString(sum[:]),
+			Verses: len(chapterVerses(chapter)),
+		})
+	}
+	data, err := marshal(index, enc.Compact)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	return writeShard(filepath.Join(dir, IndexName), data, enc.Gzip)
+}
+
+func marshal(v any, compact bool) ([]byte, error) {
+	if compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+func writeShard(path string, data []byte, gz bool) error {
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	if !gz {
+		return nil
+	}
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	zw.Write(data)
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	if err := os.WriteFile(path+".gz", buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	return nil
+}
+
+// ReadShards reads a sharded book back from dir, checking each chapter
+// file against the size and hash in the index.
+func ReadShards(dir string) (*Book, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	index := ShardIndex{Book: &Book{}}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Join(dir, IndexName), err)
+	}
+	book := index.Book
+	book.Chapters = []*Chapter{}
+	for _, shard := range index.Chapters {
+		path := filepath.Join(dir, filepath.FromSlash(shard.Path))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file: %v", err)
+		}
+		sum := sha256.Sum256(data)
+		if len(data) != shard.Size || hex.EncodeToString(sum[:]) != shard.Hash {
+			return nil, fmt.Errorf("%s: size or hash differs from %s", path, IndexName)
+		}
+		chapter := &Chapter{}
+		if err := json.Unmarshal(data, chapter); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		book.Chapters = append(book.Chapters, chapter)
+	}
+	return book, nil
+}
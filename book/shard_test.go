@@ -0,0 +1,87 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestShardsRoundTrip(t *testing.T) {
+	for _, enc := range []Encoding{{}, {Compact: true}, {Compact: true, Gzip: true}} {
+		dir := filepath.Join(t.TempDir(), "test")
+		if err := WriteShards(dir, testBook(), enc); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadShards(dir)
+		if err != nil {
+			t.Fatalf("%+v: %v", enc, err)
+		}
+		if want := testBook(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%+v: book read back differs: %v", enc, Diff(want, got))
+		}
+		for _, chapter := range testBook().Chapters {
+			data, err := os.ReadFile(filepath.Join(dir, chapter.Slug+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := map[string]any{}
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			eachWord(decoded, func(w map[string]any) {
+				if gloss, ok := w["gloss"]; enc.Compact && ok && gloss == "" {
+					t.Errorf("%+v: %s keeps the empty gloss of %v", enc, chapter.Slug, w["word"])
+				}
+				if _, ok := w["g"]; ok {
+					t.Errorf("%+v: %s refers to a gloss table", enc, chapter.Slug)
+				}
+			})
+			if _, err := os.Stat(filepath.Join(dir, chapter.Slug+".json.gz")); os.IsNotExist(err) == enc.Gzip {
+				t.Errorf("%+v: %s gzip copy present is %t", enc, chapter.Slug, !enc.Gzip)
+			}
+		}
+	}
+}
+
+func TestReadShardsChecksHash(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test")
+	if err := WriteShards(dir, testBook(), Encoding{Compact: true}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "chapter-2.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, bytes.Replace(data, []byte("and"), []byte("but"), 1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadShards(dir); err == nil {
+		t.Error("ReadShards read a chapter whose hash differs from the index")
+	}
+}
+
+// TestWriteShardsReplacesOld checks that chapter files left by an earlier
+// build of a longer book are removed.
+func TestWriteShardsReplacesOld(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test")
+	if err := WriteShards(dir, testBook(), Encoding{Gzip: true}); err != nil {
+		t.Fatal(err)
+	}
+	b := testBook()
+	b.Chapters = b.Chapters[:1]
+	if err := WriteShards(dir, b, Encoding{}); err != nil {
+		t.Fatal(err)
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "chapter-1.json"), filepath.Join(dir, IndexName)}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("shard files = %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,68 @@
+package book
+
+import (
+	"regexp"
+)
+
+// Speaker is one of the dramatis personae of a dialogue, listed under the
+// abbreviation that marks a change of speaker in the source.
+type Speaker struct {
+	Abbrev string `json:"abbrev"`
+	Name   string `json:"name"`
+	Gloss  string `json:"gloss"`
+}
+
+// knownSpeakers maps the speaker abbreviations used in the Plato sources to
+// their full names. A manifest can add to or override these.
+var knownSpeakers = map[string]Speaker{
+	"ΣΩ.":   {Name: "Σωκράτης", Gloss: "Socrates"},
+	"ΚΡ.":   {Name: "Κρίτων", Gloss: "Crito"},
+	"ΜΕΝ.":  {Name: "Μένων", Gloss: "Meno"},
+	"ΠΑΙ.":  {Name: "Παῖς", Gloss: "Meno's slave"},
+	"ΑΝ.":   {Name: "Ἄνυτος", Gloss: "Anytus"},
+	"ΕΥΘ.":  {Name: "Εὐθύφρων", Gloss: "Euthyphro"},
+	"ΙΩΝ.":  {Name: "Ἴων", Gloss: "Ion"},
+	"ΦΑΙΔ.": {Name: "Φαίδων", Gloss: "Phaedo"},
+	"ΕΧ.":   {Name: "Ἐχεκράτης", Gloss: "Echecrates"},
+	"ΚΕ.":   {Name: "Κέβης", Gloss: "Cebes"},
+	"ΣΙ.":   {Name: "Σιμμίας", Gloss: "Simmias"},
+	"ΑΠΟΛ.": {Name: "Ἀπολλόδωρος", Gloss: "Apollodorus"},
+	"ΕΤΑΙ.": {Name: "Ἑταῖρος", Gloss: "Companion"},
+	"ΑΓ.":   {Name: "Ἀγάθων", Gloss: "Agathon"},
+	"ΑΛΚ.":  {Name: "Ἀλκιβιάδης", Gloss: "Alcibiades"},
+	"ΑΡ.":   {Name: "Ἀριστοφάνης", Gloss: "Aristophanes"},
+	"ΜΕ.":   {Name: "Μέλητος", Gloss: "Meletus"},
+	"ΚΕΦ.":  {Name: "Κέφαλος", Gloss: "Cephalus"},
+	"ΠΟΛ.":  {Name: "Πολέμαρχος", Gloss: "Polemarchus"},
+	"ΘΡΑ.":  {Name: "Θρασύμαχος", Gloss: "Thrasymachus"},
+	"ΓΛ.":   {Name: "Γλαύκων", Gloss: "Glaucon"},
+	"ΑΔ.":   {Name: "Ἀδείμαντος", Gloss: "Adeimantus"},
+}
+
+var speakerRegex = regexp.MustCompile(`^\p{Lu}{2,}\.$`)
+
+// speakerLine reports whether a verse marks a change of speaker, and if so
+// returns the speaker's abbreviation. Such a verse is numbered 0 within
+// its section, as in "002.00 ΚΡ.", and its words are only an abbreviation
+// such as "ΣΩ.".
+func speakerLine(ref Ref, words []Word) (string, bool) {
+	if n := len(ref.Levels); n < 2 || ref.Levels[n-1] != 0 || len(words) != 1 {
+		return "", false
+	}
+	abbrev := words[0].Written()
+	if !speakerRegex.MatchString(abbrev) {
+		return "", false
+	}
+	return abbrev, true
+}
+
+// lookupSpeaker returns the speaker for abbrev, preferring the manifest's
+// own table.
+func lookupSpeaker(abbrev string, overrides map[string]Speaker) (Speaker, bool) {
+	s, ok := overrides[abbrev]
+	if !ok {
+		s, ok = knownSpeakers[abbrev]
+	}
+	s.Abbrev = abbrev
+	return s, ok
+}
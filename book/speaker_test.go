@@ -0,0 +1,109 @@
+package book
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpeakerLine(t *testing.T) {
+	tests := []struct {
+		ref   Ref
+		words []Word
+		want  string
+		ok    bool
+	}{
+		{NewRef(1, 0), []Word{{Word: "ΣΩ", Post: "."}}, "ΣΩ.", true},
+		{NewRef(12, 0), []Word{{Word: "ΑΠΟΛ", Post: "."}}, "ΑΠΟΛ.", true},
+		{NewRef(1, 2, 0), []Word{{Word: "ΚΡ", Post: "."}}, "ΚΡ.", true},
+		{NewRef(1, 1), []Word{{Word: "ΤΕΛΟΣ", Post: "."}}, "", false},
+		{NewRef(0), []Word{{Word: "ΣΩ", Post: "."}}, "", false},
+		{Ref{}, []Word{{Word: "ΣΩ", Post: "."}}, "", false},
+		{NewRef(1, 0), []Word{{Word: "ΣΩ"}}, "", false},
+		{NewRef(1, 0), []Word{{Word: "Σω", Post: "."}}, "", false},
+		{NewRef(1, 0), []Word{{Word: "Σ", Post: "."}}, "", false},
+		{NewRef(1, 0), []Word{{Word: "ΣΩ", Post: "."}, {Word: "ναί"}}, "", false},
+		{NewRef(1, 0), []Word{{Word: "ΣΩ", Post: ".»"}}, "", false},
+		{NewRef(1, 0), nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := speakerLine(tt.ref, tt.words)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("speakerLine(%s, %+v) = %q, %t, want %q, %t", tt.ref, tt.words, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+const dialogueSource = `001.00 {43a} ΣΩ.
+001.01 Τί τηνικάδε ἀφῖξαι, ὦ Κρίτων;
+001.02 ἢ οὐ πρῲ ἔτι ἐστίν;
+002.00 ΚΡ.
+002.01 Πάνυ μὲν οὖν.
+003.00 ΞΕ.
+003.01 ΤΕΛΟΣ.
+`
+
+func TestDialogue(t *testing.T) {
+	b, diags := convertSource(t, `{"title": "Test", "dialogue": true, "speakers": {"ΞΕ.": {"name": "Ξένος", "gloss": "Stranger"}}}`, dialogueSource)
+	if len(diags) != 0 {
+		t.Errorf("unexpected warnings %q", messages(diags))
+	}
+	wantSpeakers := []Speaker{
+		{Abbrev: "ΣΩ.", Name: "Σωκράτης", Gloss: "Socrates"},
+		{Abbrev: "ΚΡ.", Name: "Κρίτων", Gloss: "Crito"},
+		{Abbrev: "ΞΕ.", Name: "Ξένος", Gloss: "Stranger"},
+	}
+	if !reflect.DeepEqual(b.Speakers, wantSpeakers) {
+		t.Errorf("Speakers = %+v, want %+v", b.Speakers, wantSpeakers)
+	}
+	// Each turn is one content item, whatever the number of its verses.
+	type turn struct {
+		speaker string
+		verses  int
+	}
+	turns := []turn{}
+	for _, item := range b.Chapters[0].Content {
+		for _, p := range item.Paragraph {
+			if p.Speaker != item.Speaker {
+				t.Errorf("verse %s is given to %s in a turn of %s", p.Ref, p.Speaker, item.Speaker)
+			}
+		}
+		turns = append(turns, turn{item.Speaker, len(item.Paragraph)})
+	}
+	want := []turn{{"ΣΩ.", 2}, {"ΚΡ.", 1}, {"ΞΕ.", 1}} // ΤΕΛΟΣ. is not numbered 0
+	if !reflect.DeepEqual(turns, want) {
+		t.Errorf("turns = %+v, want %+v", turns, want)
+	}
+	// The milestone on the speaker's line goes to the first word spoken.
+	if w := b.Chapters[0].Content[0].Paragraph[0].Words[0]; w.Stephanus != "43a" {
+		t.Errorf("first word %+v has no Stephanus anchor", w)
+	}
+}
+
+func TestDialogueUnknownSpeaker(t *testing.T) {
+	b, diags := convertSource(t, `{"title": "Test", "dialogue": true}`, "001.00 ΞΕ.\n001.01 Χαῖρε.\n")
+	if want := []string{"test.txt:1: unknown speaker ΞΕ."}; !reflect.DeepEqual(messages(diags), want) {
+		t.Errorf("got %q, want %q", messages(diags), want)
+	}
+	if want := []Speaker{{Abbrev: "ΞΕ."}}; !reflect.DeepEqual(b.Speakers, want) {
+		t.Errorf("Speakers = %+v, want %+v", b.Speakers, want)
+	}
+}
+
+// TestNotDialogue checks that a verse of one capitalized word is kept as a
+// verse in a book that is not a dialogue.
+func TestNotDialogue(t *testing.T) {
+	b, _ := convertSource(t, `{"title": "Test"}`, dialogueSource)
+	if len(b.Speakers) != 0 {
+		t.Errorf("Speakers = %+v, want none", b.Speakers)
+	}
+	verses := 0
+	for _, item := range b.Chapters[0].Content {
+		if item.Speaker != "" {
+			t.Errorf("content item given to %s", item.Speaker)
+		}
+		verses += len(item.Paragraph)
+	}
+	if verses != 7 {
+		t.Errorf("got %d verses, want all 7", verses)
+	}
+}
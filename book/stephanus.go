@@ -0,0 +1,81 @@
+package book
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Stephanus is a page and section of the Stephanus edition of Plato, such
+// as 43a, by which the dialogues are conventionally cited.
+type Stephanus struct {
+	Page    int
+	Section string
+}
+
+var stephanusRegex = regexp.MustCompile(`^([0-9]+)([a-e])$`)
+
+// ParseStephanus parses a Stephanus number such as "43a" or "327b".
+func ParseStephanus(s string) (Stephanus, error) {
+	matches := stephanusRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return Stephanus{}, fmt.Errorf("invalid Stephanus number %q", s)
+	}
+	page, _ := strconv.Atoi(matches[1])
+	return Stephanus{Page: page, Section: matches[2]}, nil
+}
+
+func (s Stephanus) String() string {
+	return fmt.Sprintf("%d%s", s.Page, s.Section)
+}
+
+// StephanusAnchor locates the word at which a Stephanus section begins.
+type StephanusAnchor struct {
+	Stephanus string `json:"stephanus"`
+	Chapter   string `json:"chapter"`
+	VerseID   int    `json:"verse_id"`
+	Word      int    `json:"word"`
+}
+
+// indexStephanus collects the anchors of the book's Stephanus sections in
+// reading order. It must run after numberVerses.
+func indexStephanus(book *Book) {
+	book.Stephanus = nil
+	for _, chapter := range book.Chapters {
+		for _, item := range chapter.Content {
+			for _, p := range item.Paragraph {
+				for i, w := range p.Words {
+					if w.Stephanus == "" {
+						continue
+					}
+					book.Stephanus = append(book.Stephanus, StephanusAnchor{
+						Stephanus: w.Stephanus,
+						Chapter:   chapter.Slug,
+						VerseID:   p.VerseID,
+						Word:      i,
+					})
+				}
+			}
+		}
+	}
+}
+
+// FindStephanus returns the anchor for a Stephanus citation such as "46b".
+// A bare page number such as "46" finds the first section of that page.
+func (b *Book) FindStephanus(cite string) (StephanusAnchor, bool) {
+	cite = strings.TrimSpace(cite)
+	for _, a := range b.Stephanus {
+		if a.Stephanus == cite {
+			return a, true
+		}
+	}
+	if _, err := strconv.Atoi(cite); err == nil {
+		for _, a := range b.Stephanus {
+			if strings.TrimRight(a.Stephanus, "abcde") == cite {
+				return a, true
+			}
+		}
+	}
+	return StephanusAnchor{}, false
+}
@@ -0,0 +1,66 @@
+package book
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStephanus(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Stephanus
+	}{
+		{"43a", Stephanus{Page: 43, Section: "a"}},
+		{"327b", Stephanus{Page: 327, Section: "b"}},
+		{" 17e ", Stephanus{Page: 17, Section: "e"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseStephanus(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseStephanus(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+		if s := got.String(); s != tt.want.String() {
+			t.Errorf("String() = %q", s)
+		}
+	}
+	for _, in := range []string{"", "43", "a", "43f", "43A", "43ab", "a43", "-4a", "43 a"} {
+		if got, err := ParseStephanus(in); err == nil {
+			t.Errorf("ParseStephanus(%q) = %+v, want an error", in, got)
+		}
+	}
+}
+
+func TestFindStephanus(t *testing.T) {
+	b, _ := convertSource(t, `{"title": "Test"}`,
+		"1.1 {17a} Ὅτι μὲν ὑμεῖς, {17b} ὦ ἄνδρες\n1.2 Ἀθηναῖοι, πεπόνθατε\n2.1 {18a} καὶ {18c} ἐγὼ δ’ οὖν\n")
+	want := []StephanusAnchor{
+		{Stephanus: "17a", Chapter: "chapter-1", VerseID: 1, Word: 0},
+		{Stephanus: "17b", Chapter: "chapter-1", VerseID: 1, Word: 3},
+		{Stephanus: "18a", Chapter: "chapter-1", VerseID: 3, Word: 0},
+		{Stephanus: "18c", Chapter: "chapter-1", VerseID: 3, Word: 1},
+	}
+	if !reflect.DeepEqual(b.Stephanus, want) {
+		t.Fatalf("Stephanus = %+v, want %+v", b.Stephanus, want)
+	}
+	tests := []struct {
+		cite string
+		want string
+		ok   bool
+	}{
+		{"17b", "17b", true},
+		{" 18c ", "18c", true},
+		{"18", "18a", true},
+		{"17", "17a", true},
+		{"18b", "", false},
+		{"19", "", false},
+		{"17f", "", false},
+		{"a", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		a, ok := b.FindStephanus(tt.cite)
+		if ok != tt.ok || a.Stephanus != tt.want {
+			t.Errorf("FindStephanus(%q) = %+v, %t, want %s, %t", tt.cite, a, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ValidateFile checks that a book file is of the current schema version,
+// then checks it against the schema of the book format and for the mistakes
+// the schema cannot catch: books without chapters, repeated chapter slugs,
+// empty chapters, verses out of order, parts that do not match the
+// chapters and images that do not exist. It returns a description of each
+// problem found.
+func ValidateFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	decoded, err := decodeRaw(bytes.NewReader(data))
+	if err != nil {
+		return []string{fmt.Sprintf("invalid JSON: %v", err)}, nil
+	}
+	version, err := Version(decoded)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+	if version < SchemaVersion {
+		return []string{fmt.Sprintf("schema version %d is out of date; run grbook migrate", version)}, nil
+	}
+	if version > SchemaVersion {
+		return []string{fmt.Sprintf("schema version %d is newer than %d, the latest this grbook supports", version, SchemaVersion)}, nil
+	}
+	schema := Schema()
+	v := &validator{defs: schema["$defs"].(map[string]any)}
+	v.conform(decoded, schema, "$")
+	if len(v.problems) > 0 {
+		return v.problems, nil
+	}
+
+	if data, err = json.Marshal(decoded); err != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	book := &Book{}
+	if err := json.Unmarshal(data, book); err != nil {
+		return []string{fmt.Sprintf("invalid book: %v", err)}, nil
+	}
+	return checkBook(book, filepath.Dir(path)), nil
+}
+
+// checkBook reports the problems with a book that conforms to the schema.
+// Images are looked for relative to dir.
+func checkBook(book *Book, dir string) []string {
+	problems := []string{}
+	if len(book.Chapters) == 0 {
+		problems = append(problems, "book has no chapters")
+	}
+	image := func(name, where string) {
+		if name == "" || strings.Contains(name, "://") {
+			return
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: image %s not found", where, name))
+		}
+	}
+	image(book.CoverImage, "coverImage")
+	for _, t := range book.Thumbnails {
+		image(t.URL, "thumbnail")
+	}
+	for _, item := range book.Vocab {
+		image(item.Image, "vocab "+item.Word)
+	}
+
+	slugs := map[string]int{}
+	var last *Ref
+	for i, chapter := range book.Chapters {
+		where := fmt.Sprintf("chapter %d (%s)", i+1, chapter.Slug)
+		if j, ok := slugs[chapter.Slug]; ok {
+			problems = append(problems, fmt.Sprintf("%s: slug already used by chapter %d", where, j))
+		} else {
+			slugs[chapter.Slug] = i + 1
+		}
+		image(chapter.TitleImage, where+": titleImage")
+		for _, item := range chapter.Vocab {
+			image(item.Image, fmt.Sprintf("%s: vocab %s", where, item.Word))
+		}
+		verses := 0
+		for _, item := range chapter.Content {
+			image(item.Image, where+": content")
+			for _, p := range item.Paragraph {
+				if len(p.Words) > 0 {
+					verses++
+				}
+				if p.Ref == nil {
+					continue
+				}
+				if last != nil && p.Ref.Compare(*last) <= 0 {
+					problems = append(problems, fmt.Sprintf("%s: verse %s comes after %s", where, p.Ref, last))
+				}
+				last = p.Ref
+			}
+		}
+		if verses == 0 {
+			problems = append(problems, where+": chapter has no verses")
+		}
+	}
+	return append(problems, checkParts(book)...)
+}
+
+// validator checks a decoded JSON value against the schema returned by
+// Schema, which uses only $ref, type, properties, required,
+// additionalProperties and items.
+type validator struct {
+	defs     map[string]any
+	problems []string
+}
+
+func (v *validator) conform(value any, schema map[string]any, at string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	}
+	want := schema["type"].(string)
+	if got := jsonType(value); got != want {
+		v.problems = append(v.problems, fmt.Sprintf("%s: expected %s, found %s", at, want, got))
+		return
+	}
+	switch want {
+	case "array":
+		items := schema["items"].(map[string]any)
+		for i, elem := range value.([]any) {
+			v.conform(elem, items, fmt.Sprintf("%s[%d]", at, i))
+		}
+	case "object":
+		obj := value.(map[string]any)
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]string)
+		for _, key := range required {
+			if _, ok := obj[key]; !ok {
+				v.problems = append(v.problems, fmt.Sprintf("%s: missing %s", at, key))
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if prop, ok := properties[key]; ok {
+				v.conform(obj[key], prop.(map[string]any), at+"."+key)
+			} else if extra, ok := schema["additionalProperties"].(map[string]any); ok {
+				v.conform(obj[key], extra, at+"."+key)
+			} else {
+				v.problems = append(v.problems, fmt.Sprintf("%s: unexpected field %s", at, key))
+			}
+		}
+	}
+}
+
+// jsonType names the JSON type of a value decoded with UseNumber.
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
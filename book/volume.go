@@ -0,0 +1,162 @@
+package book
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// ManifestVolume names one volume of a work made of several, such as a
+// book of the Paidagogos, by the directory of the volume's own manifest
+// relative to the work's. Title and Gloss title the volume in the work and
+// default to the volume's Greek and English titles.
+type ManifestVolume struct {
+	Dir   string `json:"dir"`
+	Title string `json:"title,omitempty"`
+	Gloss string `json:"gloss,omitempty"`
+}
+
+// LoadVolumes loads the manifests of the work's volumes.
+func (m *Manifest) LoadVolumes() ([]*Manifest, error) {
+	volumes := []*Manifest{}
+	for _, v := range m.Volumes {
+		vm, err := LoadManifest(filepath.Join(m.Dir, v.Dir))
+		if err != nil {
+			return nil, fmt.Errorf("%s: volume %s: %v", m.Dir, v.Dir, err)
+		}
+		if len(vm.Volumes) > 0 {
+			return nil, fmt.Errorf("%s: volume %s has volumes of its own", m.Dir, v.Dir)
+		}
+		volumes = append(volumes, vm)
+	}
+	return volumes, nil
+}
+
+// convertVolumes converts each volume of a work as a book of its own and
+// joins them into one. Each volume becomes a top-level part of the work,
+// holding the volume's own parts and chapters, so that the work reads
+// volume, chapter, section. Chapters and parts are renumbered through the
+// work. With the manifest's PrefixRefs each volume's number is put in front
+// of its refs, so that refs that start afresh in each volume run on
+// through the work. The volumes' vocabularies are combined in the work's Vocab,
+// and the work's Sections, if any, split the chapters of every volume.
+func convertVolumes(m *Manifest) (*Book, []Diagnostic, error) {
+	volumes, err := m.LoadVolumes()
+	if err != nil {
+		return nil, nil, err
+	}
+	work := m.NewBook()
+	work.Parts = []*Part{}
+	diags := []Diagnostic{}
+	for i, vm := range volumes {
+		vol, d, err := Convert(vm)
+		if err != nil {
+			return nil, nil, err
+		}
+		diags = append(diags, d...)
+		if m.PrefixRefs {
+			prefixRefs(vol, i+1)
+		}
+
+		part := newPart(countParts(work.Parts)+1, "")
+		part.Title = volumeTitle(m.Volumes[i], vm)
+		slugs := map[string]string{}
+		for _, chapter := range vol.Chapters {
+			slugs[chapter.Slug] = fmt.Sprintf("chapter-%d", len(work.Chapters)+1)
+			chapter.Slug = slugs[chapter.Slug]
+			work.Chapters = append(work.Chapters, chapter)
+		}
+		n := countParts(work.Parts) + 1
+		eachPart(vol.Parts, func(p *Part) {
+			n++
+			p.Slug = fmt.Sprintf("part-%d", n)
+			for j, slug := range p.Chapters {
+				p.Chapters[j] = slugs[slug]
+			}
+		})
+		part.Parts = vol.Parts
+		inPart := partOf(vol.Parts)
+		for _, chapter := range vol.Chapters {
+			if inPart[chapter.Slug] == nil {
+				part.Chapters = append(part.Chapters, chapter.Slug)
+			}
+		}
+		work.Parts = append(work.Parts, part)
+		for _, s := range vol.Speakers {
+			if !hasSpeaker(work.Speakers, s.Abbrev) {
+				work.Speakers = append(work.Speakers, s)
+			}
+		}
+		work.Vocab = combineVocab(work.Vocab, vol)
+	}
+	if m.Sections != nil {
+		if err := m.Sections.check(); err != nil {
+			return nil, nil, fmt.Errorf("%s: sections: %v", m.Dir, err)
+		}
+		splitSections(work, *m.Sections)
+	}
+	numberVerses(work)
+	indexStephanus(work)
+	return work, diags, nil
+}
+
+// volumeTitle titles a volume in its work: by the work manifest's entry
+// for it if that names one, or else by the volume's Greek title with its
+// English title as the gloss. A volume with no Greek title has only the
+// gloss.
+func volumeTitle(v ManifestVolume, vm *Manifest) Title {
+	title := Title{Display: v.Title, Gloss: v.Gloss}
+	if title.Display == "" {
+		title.Display = vm.GreekTitle
+		if title.Gloss == "" {
+			title.Gloss = vm.Title
+		}
+	}
+	return title
+}
+
+// prefixRefs puts n in front of every ref in the book.
+func prefixRefs(book *Book, n int) {
+	for _, chapter := range book.Chapters {
+		for i := range chapter.Content {
+			for j := range chapter.Content[i].Paragraph {
+				p := &chapter.Content[i].Paragraph[j]
+				if p.Ref != nil {
+					ref := NewRef(append([]int{n}, p.Ref.Levels...)...)
+					p.Ref = &ref
+				}
+			}
+		}
+	}
+}
+
+func hasSpeaker(speakers []Speaker, abbrev string) bool {
+	for _, s := range speakers {
+		if s.Abbrev == abbrev {
+			return true
+		}
+	}
+	return false
+}
+
+// combineVocab adds to vocab each word glossed in the book's chapters that
+// it does not already hold, comparing words as greek.Fold does.
+func combineVocab(vocab []VocabItem, book *Book) []VocabItem {
+	seen := map[string]bool{}
+	for _, item := range vocab {
+		seen[greek.Fold(item.Word)] = true
+	}
+	for _, chapter := range book.Chapters {
+		for _, item := range chapter.Vocab {
+			key := greek.Fold(strings.TrimSpace(item.Word))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			vocab = append(vocab, item)
+		}
+	}
+	return vocab
+}
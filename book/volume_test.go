@@ -0,0 +1,167 @@
+package book
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeWork makes a work of two volumes: the sample sectioned book, and a
+// book in the Stoffel scheme in two parts. It returns the work's manifest.
+func writeWork(t *testing.T, work string) *Manifest {
+	t.Helper()
+	sections, err := os.ReadFile(filepath.Join("testdata", "sections.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		ManifestName:                       work,
+		filepath.Join("one", ManifestName): `{"title": "Book 1", "greekTitle": "Βιβλίον αʹ", "format": "sections"}`,
+		filepath.Join("one", "one.txt"):    string(sections),
+		filepath.Join("two", ManifestName): `{"title": "Book 2", "scheme": "stoffel", "grouping": "titles"}`,
+		filepath.Join("two", "two.txt"):    "0.0 FIRST PART\n1.title.grc Α\n1.1 ἐν ἀρχῇ\n0.0 SECOND PART\n2.title.grc Β\n2.1 ἦν ὁ λόγος\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestConvertVolumes(t *testing.T) {
+	m := writeWork(t, `{"title": "Work", "volumes": [{"dir": "one"}, {"dir": "two", "title": "Βιβλίον βʹ"}], "prefixRefs": true}`)
+	b, _, err := Convert(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each volume is a part holding its own parts, renumbered through the
+	// work, and its chapters.
+	want := []string{
+		"part-1 Βιβλίον αʹ|Book 1 [chapter-1 chapter-2]",
+		"part-2 Βιβλίον βʹ| []",
+		"  part-3 FIRST PART|FIRST PART [chapter-3]",
+		"  part-4 SECOND PART|SECOND PART [chapter-4]",
+	}
+	if got := describeParts(b.Parts, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("parts:\n%q\nwant:\n%q", got, want)
+	}
+	if problems := checkParts(b); len(problems) != 0 {
+		t.Errorf("checkParts = %q", problems)
+	}
+	// Refs carry the volume's number and verses are numbered through the
+	// work.
+	verses := []string{}
+	for _, c := range b.Chapters {
+		for _, p := range chapterVerses(c) {
+			verses = append(verses, fmt.Sprintf("%s %s %d", c.Slug, p.Ref, p.VerseID))
+		}
+	}
+	wantVerses := []string{
+		"chapter-1 1.1.1 1", "chapter-1 1.1.2 2", "chapter-1 1.1.3 3", "chapter-1 1.1.4 4",
+		"chapter-2 1.2.1 5", "chapter-3 2.1.1 6", "chapter-4 2.2.1 7",
+	}
+	if !reflect.DeepEqual(verses, wantVerses) {
+		t.Errorf("verses = %q, want %q", verses, wantVerses)
+	}
+	if want := []VocabItem{{Word: "φιλόσοφος", Gloss: "philosopher", Image: "philosopher.png"}, {Word: "ἀγορά", Gloss: "marketplace"}}; !reflect.DeepEqual(b.Vocab, want) {
+		t.Errorf("Vocab = %+v, want %+v", b.Vocab, want)
+	}
+}
+
+func TestConvertVolumesWithoutPrefix(t *testing.T) {
+	m := writeWork(t, `{"title": "Work", "volumes": [{"dir": "one"}, {"dir": "two"}], "sections": {"by": "verses", "size": 2}}`)
+	b, _, err := Convert(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := chapterVerses(b.Chapters[2])[0].Ref; ref.String() != "1.1" {
+		t.Errorf("first verse of the second volume is %s, want 1.1", ref)
+	}
+	// The work's sections split the chapters of every volume.
+	if got := b.Chapters[0].Content[0].Subtitle; got != "Ἐν τῇ ἀγορᾷ" {
+		t.Errorf("a subtitle the source gives became %q", got)
+	}
+	if got := b.Chapters[0].Content[1].Subtitle; got != "Section 3-4" {
+		t.Errorf("second section of the first chapter titled %q", got)
+	}
+	if got := b.Chapters[3].Content[0].Subtitle; got != "Section 1" {
+		t.Errorf("section of the last chapter titled %q", got)
+	}
+}
+
+func TestLoadVolumesRefusesNesting(t *testing.T) {
+	m := writeWork(t, `{"title": "Work", "volumes": [{"dir": "one"}]}`)
+	os.WriteFile(filepath.Join(m.Dir, "one", ManifestName), []byte(`{"title": "Book 1", "volumes": [{"dir": "../two"}]}`), 0o644)
+	if _, err := m.LoadVolumes(); err == nil || !strings.Contains(err.Error(), "volumes of its own") {
+		t.Errorf("LoadVolumes = %v, want an error for a volume with volumes", err)
+	}
+	m.Volumes = []ManifestVolume{{Dir: "three"}}
+	if _, err := m.LoadVolumes(); err == nil {
+		t.Error("LoadVolumes found a volume that does not exist")
+	}
+}
+
+func TestVolumeTitle(t *testing.T) {
+	vm := &Manifest{Title: "Book 1", GreekTitle: "Βιβλίον αʹ"}
+	tests := []struct {
+		v    ManifestVolume
+		vm   *Manifest
+		want Title
+	}{
+		{ManifestVolume{}, vm, Title{Display: "Βιβλίον αʹ", Gloss: "Book 1"}},
+		{ManifestVolume{Gloss: "The First Book"}, vm, Title{Display: "Βιβλίον αʹ", Gloss: "The First Book"}},
+		{ManifestVolume{Title: "Λόγος αʹ"}, vm, Title{Display: "Λόγος αʹ"}},
+		{ManifestVolume{Title: "Λόγος αʹ", Gloss: "Discourse 1"}, vm, Title{Display: "Λόγος αʹ", Gloss: "Discourse 1"}},
+		{ManifestVolume{}, &Manifest{Title: "Book 1"}, Title{Gloss: "Book 1"}},
+	}
+	for _, tt := range tests {
+		if got := volumeTitle(tt.v, tt.vm); got != tt.want {
+			t.Errorf("volumeTitle(%+v) = %+v, want %+v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixRefs(t *testing.T) {
+	ref := NewRef(3, 14)
+	b := &Book{Chapters: []*Chapter{NewChapter("chapter-1", "")}}
+	b.Chapters[0].Content = []ContentItem{{Paragraph: []Paragraph{{Ref: &ref}, {}}}}
+	prefixRefs(b, 2)
+	p := b.Chapters[0].Content[0].Paragraph
+	if want := NewRef(2, 3, 14); !reflect.DeepEqual(*p[0].Ref, want) {
+		t.Errorf("prefixed ref = %+v, want %+v", *p[0].Ref, want)
+	}
+	if p[1].Ref != nil {
+		t.Errorf("a paragraph without a ref was given %s", p[1].Ref)
+	}
+	if ref.String() != "3.14" {
+		t.Errorf("prefixRefs changed the volume's own ref to %s", ref)
+	}
+}
+
+func TestCombineVocab(t *testing.T) {
+	chapter := NewChapter("chapter-1", "")
+	chapter.Vocab = []VocabItem{
+		{Word: "Λόγος", Gloss: "Word"},
+		{Word: " ἀρχή ", Gloss: "beginning"},
+		{Word: "ἀρχὴ", Gloss: "rule"},
+		{Word: " ", Gloss: "nothing"},
+	}
+	vocab := []VocabItem{{Word: "λόγος", Gloss: "word"}}
+	want := []VocabItem{{Word: "λόγος", Gloss: "word"}, {Word: " ἀρχή ", Gloss: "beginning"}}
+	if got := combineVocab(vocab, &Book{Chapters: []*Chapter{chapter}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("combineVocab = %+v, want %+v", got, want)
+	}
+}
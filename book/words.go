@@ -0,0 +1,50 @@
+package book
+
+import (
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+// wordList collects the words of a verse from successive pieces of its
+// text, attaching punctuation that stands on its own, as in
+// "Φαίδων {/pers} ,", to the word beside it.
+type wordList struct {
+	words   []Word
+	pending string // punctuation waiting for the verse's first word
+}
+
+// add tokenizes text and appends its words, made from the tokens by word.
+func (l *wordList) add(text string, word func(greek.Token) Word) {
+	for _, t := range greek.Tokenize(text) {
+		space := ""
+		if t.Space != "" {
+			space = " "
+		}
+		switch {
+		case t.Form != "":
+			if l.pending != "" {
+				t.Pre = l.pending + space + t.Pre
+				l.pending = ""
+			}
+			l.words = append(l.words, word(t))
+		case t.Pre == "":
+			// whitespace at the end of the text
+		case len(l.words) > 0:
+			l.words[len(l.words)-1].Post += space + t.Pre
+		default:
+			l.pending += t.Pre
+		}
+	}
+}
+
+// done returns the words. Punctuation with no word to attach to is kept as
+// a word of its own with an empty form.
+func (l *wordList) done() []Word {
+	words := l.words
+	if words == nil {
+		words = []Word{}
+	}
+	if l.pending != "" {
+		words = append(words, Word{Word: "", Gloss: "", Pre: l.pending})
+	}
+	return words
+}
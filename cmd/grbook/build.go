@@ -0,0 +1,222 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mmccray/GradedReaderBooks/book"
+)
+
+// job is one book to build and, once built, what came of it.
+type job struct {
+	manifest *book.Manifest
+	inputs   cacheEntry
+	reason   string
+	size     int64 // of the source, to start the largest books first
+
+	diags []book.Diagnostic
+	err   error
+}
+
+// widthList gives thumbnail widths as the -thumbs flag takes them.
+func widthList(widths []int) string {
+	list := make([]string, len(widths))
+	for i, w := range widths {
+		list[i] = strconv.Itoa(w)
+	}
+	return strings.Join(list, ",")
+}
+
+func runBuild(args []string) error {
+	config, err := book.LoadConfig(".")
+	if err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	force := flags.Bool("force", false, "rebuild every book, even if its inputs are unchanged")
+	workers := flags.Int("j", runtime.GOMAXPROCS(0), "number of books to build at once")
+	compact := flags.Bool("compact", false, "write compact JSON with a table of glosses")
+	gz := flags.Bool("gzip", false, "also write a gzip-compressed copy of each book")
+	shards := flags.Bool("shard", false, "also write each book as an index and a file per chapter")
+	coverURL := flags.String("cover-url", config.CoverURL, "publish covers under this URL of the current directory; \"\" to give them by relative path")
+	thumbs := flags.String("thumbs", widthList(config.Thumbs), "comma-separated widths of the cover thumbnails to make")
+	catalog := flags.String("catalog", book.CatalogName, "rewrite the catalog of the library at this path after building; \"\" to leave it")
+	flags.Parse(args)
+	enc := book.Encoding{Compact: *compact, Gzip: *gz, Shards: *shards}
+	if *workers < 1 {
+		return fmt.Errorf("-j must be at least 1")
+	}
+	collections, err := book.FindCollections(".")
+	if err != nil {
+		return err
+	}
+	config.CoverURL, config.Thumbs = *coverURL, []int{}
+	for _, w := range strings.Split(*thumbs, ",") {
+		if w = strings.TrimSpace(w); w == "" {
+			continue
+		}
+		n, err := strconv.Atoi(w)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid thumbnail width %q", w)
+		}
+		config.Thumbs = append(config.Thumbs, n)
+	}
+	covers := config.CoverOptions(".", collections)
+
+	manifests, err := findBooks(flags.Args())
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("no %s found", book.ManifestName)
+	}
+	cache := loadCache(cacheName)
+	jobs := []*job{}
+	for _, m := range manifests {
+		in, err := inputs(m, enc, covers)
+		if err != nil {
+			return err
+		}
+		reason := cache.stale(m, in, covers.Widths)
+		if *force {
+			reason = "forced"
+		}
+		if reason == "" {
+			continue
+		}
+		j := &job{manifest: m, inputs: in, reason: reason}
+		if info, err := os.Stat(m.SourcePath()); err == nil {
+			j.size = info.Size()
+		}
+		jobs = append(jobs, j)
+	}
+
+	runJobs(jobs, *workers, enc, covers)
+
+	// Results are reported in library order, whatever order the books
+	// finished in.
+	failed := 0
+	for _, j := range jobs {
+		fmt.Printf("Building %s: %s\n", j.manifest.Dir, j.reason)
+		for _, d := range j.diags {
+			fmt.Printf("Warning: %s\n", d)
+		}
+		if j.err != nil {
+			fmt.Printf("Error: %v\n", j.err)
+			failed++
+			continue
+		}
+		fmt.Printf("Processing complete. Output saved to %s\n", j.manifest.OutputPath())
+		cache.record(j.manifest, j.inputs)
+	}
+	fmt.Printf("%d books built, %d up to date\n", len(jobs)-failed, len(manifests)-len(jobs))
+	if err := cache.save(); err != nil {
+		return err
+	}
+	if *catalog != "" {
+		// The catalog covers the whole library, whichever books were
+		// built.
+		library, err := book.FindManifests(".")
+		if err != nil {
+			return err
+		}
+		if err := writeCatalog(*catalog, library); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d books failed to build", failed, len(jobs))
+	}
+	return nil
+}
+
+// runJobs builds the books with a pool of workers. The largest sources are
+// handed out first, so that a long book such as the Republic runs alongside
+// the short ones instead of holding up the end of the build.
+func runJobs(jobs []*job, workers int, enc book.Encoding, covers book.CoverOptions) {
+	queue := make([]*job, len(jobs))
+	copy(queue, jobs)
+	sort.SliceStable(queue, func(a, b int) bool { return queue[a].size > queue[b].size })
+
+	ch := make(chan *job)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range ch {
+				j.build(enc, covers)
+			}
+		}()
+	}
+	for _, j := range queue {
+		ch <- j
+	}
+	close(ch)
+	wg.Wait()
+}
+
+func (j *job) build(enc book.Encoding, covers book.CoverOptions) {
+	b, diags, err := book.Convert(j.manifest)
+	j.diags = diags
+	if err != nil {
+		j.err = err
+		return
+	}
+	diags, err = book.AddCover(b, j.manifest, covers)
+	j.diags = append(j.diags, diags...)
+	if err != nil {
+		j.err = err
+		return
+	}
+	j.err = book.WriteEncoded(j.manifest.OutputPath(), b, enc)
+}
+
+// findBooks loads the manifests named by each of args, defaulting to the
+// current directory. An argument is a book directory, a directory to search
+// for books, or a pattern such as "./..." or "Plato/..." that searches the
+// directory it names.
+func findBooks(args []string) ([]*book.Manifest, error) {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	manifests := []*book.Manifest{}
+	seen := map[string]bool{}
+	for _, arg := range args {
+		dir, recursive := strings.CutSuffix(filepath.ToSlash(arg), "/...")
+		if arg == "..." {
+			dir, recursive = ".", true
+		}
+		if dir == "" {
+			dir = "."
+		}
+		found := []*book.Manifest{}
+		if _, err := os.Stat(filepath.Join(dir, book.ManifestName)); err == nil && !recursive {
+			m, err := book.LoadManifest(dir)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, m)
+		} else {
+			all, err := book.FindManifests(dir)
+			if err != nil {
+				return nil, err
+			}
+			found = all
+		}
+		for _, m := range found {
+			if key := filepath.Clean(m.Dir); !seen[key] {
+				seen[key] = true
+				manifests = append(manifests, m)
+			}
+		}
+	}
+	return manifests, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mmccray/GradedReaderBooks/book"
+)
+
+// cacheName is the build cache, kept in the directory grbook runs in.
+const cacheName = ".grbook-cache.json"
+
+// cacheEntry records the inputs a book was last built from.
+type cacheEntry struct {
+	Source    string `json:"source"`
+	Manifest  string `json:"manifest"`
+	Converter int    `json:"converter"`
+	Encoding  string `json:"encoding"`
+	Cover     string `json:"cover"`
+}
+
+// buildCache maps the absolute directory of each book built to the inputs
+// it was built from.
+type buildCache struct {
+	path  string
+	Books map[string]cacheEntry `json:"books"`
+}
+
+// loadCache reads the build cache at path. A missing or unreadable cache is
+// treated as empty, so that everything is rebuilt.
+func loadCache(path string) *buildCache {
+	c := &buildCache{path: path, Books: map[string]cacheEntry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil || c.Books == nil {
+		fmt.Printf("Warning: ignoring unreadable build cache %s\n", path)
+		c.Books = map[string]cacheEntry{}
+	}
+	return c
+}
+
+func (c *buildCache) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing build cache: %v", err)
+	}
+	return nil
+}
+
+// inputs hashes the source text, manifest and cover of a book, and records
+// the encoding it is to be written in and how its cover is published,
+// including the shelf a placeholder cover names.
+func inputs(m *book.Manifest, enc book.Encoding, covers book.CoverOptions) (cacheEntry, error) {
+	source, err := hashSource(m)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+	manifest, err := hashFile(m.File)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+	cover := "none"
+	if path := m.CoverPath(); path != "" {
+		if cover, err = hashFile(path); err != nil {
+			return cacheEntry{}, err
+		}
+	}
+	return cacheEntry{
+		Source:    source,
+		Manifest:  manifest,
+		Converter: book.ConverterVersion,
+		Encoding:  fmt.Sprintf("compact=%t gzip=%t shard=%t", enc.Compact, enc.Gzip, enc.Shards),
+		Cover:     fmt.Sprintf("%s url=%s widths=%v shelf=%s", cover, covers.BaseURL, covers.Widths, m.Shelf(covers.Collections)),
+	}, nil
+}
+
+// stale says why a book with the given inputs needs building, or returns ""
+// if its output, and the thumbnails of the given widths or placeholder
+// made of its cover, are up to date.
+func (c *buildCache) stale(m *book.Manifest, in cacheEntry, widths []int) string {
+	last, ok := c.Books[bookKey(m)]
+	switch {
+	case !ok:
+		return "not built before"
+	case last.Converter != in.Converter:
+		return "converter changed"
+	case last.Manifest != in.Manifest:
+		return "manifest changed"
+	case last.Source != in.Source:
+		return "source changed"
+	case last.Encoding != in.Encoding:
+		return "encoding changed"
+	case last.Cover != in.Cover:
+		return "cover changed"
+	}
+	if _, err := os.Stat(m.OutputPath()); err != nil {
+		return "output missing"
+	}
+	files, err := m.CoverFiles(widths)
+	if err != nil {
+		return "cover unreadable"
+	}
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			return "cover output missing"
+		}
+	}
+	return ""
+}
+
+func (c *buildCache) record(m *book.Manifest, in cacheEntry) {
+	c.Books[bookKey(m)] = in
+}
+
+func bookKey(m *book.Manifest) string {
+	if abs, err := filepath.Abs(m.Dir); err == nil {
+		return abs
+	}
+	return m.Dir
+}
+
+// hashSource hashes the source text of a book, or for a work of several
+// volumes the source texts and manifests of its volumes.
+func hashSource(m *book.Manifest) (string, error) {
+	if len(m.Volumes) == 0 {
+		return hashFile(m.SourcePath())
+	}
+	volumes, err := m.LoadVolumes()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, vm := range volumes {
+		for _, path := range []string{vm.SourcePath(), vm.File} {
+			sum, err := hashFile(path)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintln(h, sum)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
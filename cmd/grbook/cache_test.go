@@ -0,0 +1,156 @@
+package main
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mmccray/GradedReaderBooks/book"
+)
+
+// testLibrary makes a library of one book with a cover, builds it as
+// grbook build does and returns its manifest and cover options.
+func testLibrary(t *testing.T) (*book.Manifest, book.CoverOptions) {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "test")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(book.ManifestName, `{"title": "Test"}`)
+	write("test.txt", "1.1 ἐν ἀρχῇ ἦν ὁ λόγος\n")
+	f, err := os.Create(filepath.Join(dir, "test.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewNRGBA(image.Rect(0, 0, 400, 600))); err != nil {
+		t.Fatal(err)
+	}
+	m, err := book.LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	covers := book.CoverOptions{Root: root, Widths: []int{200}}
+	b, _, err := book.Convert(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := book.AddCover(b, m, covers); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteFile(m.OutputPath(), b); err != nil {
+		t.Fatal(err)
+	}
+	return m, covers
+}
+
+func TestCacheStale(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(m *book.Manifest, covers *book.CoverOptions, enc *book.Encoding, last *cacheEntry)
+		want   string
+	}{
+		{"unchanged", func(*book.Manifest, *book.CoverOptions, *book.Encoding, *cacheEntry) {}, ""},
+		{"converter", func(_ *book.Manifest, _ *book.CoverOptions, _ *book.Encoding, last *cacheEntry) {
+			last.Converter--
+		}, "converter changed"},
+		{"manifest", func(m *book.Manifest, _ *book.CoverOptions, _ *book.Encoding, _ *cacheEntry) {
+			os.WriteFile(m.File, []byte(`{"title": "Other"}`), 0o644)
+		}, "manifest changed"},
+		{"source", func(m *book.Manifest, _ *book.CoverOptions, _ *book.Encoding, _ *cacheEntry) {
+			os.WriteFile(m.SourcePath(), []byte("1.1 ἐν ἀρχῇ\n"), 0o644)
+		}, "source changed"},
+		{"encoding", func(_ *book.Manifest, _ *book.CoverOptions, enc *book.Encoding, _ *cacheEntry) {
+			enc.Compact = true
+		}, "encoding changed"},
+		{"cover url", func(_ *book.Manifest, covers *book.CoverOptions, _ *book.Encoding, _ *cacheEntry) {
+			covers.BaseURL = "https://example.com"
+		}, "cover changed"},
+		{"thumbnail widths", func(_ *book.Manifest, covers *book.CoverOptions, _ *book.Encoding, _ *cacheEntry) {
+			covers.Widths = []int{100}
+		}, "cover changed"},
+		{"cover", func(m *book.Manifest, _ *book.CoverOptions, _ *book.Encoding, _ *cacheEntry) {
+			f, _ := os.Create(m.CoverPath())
+			defer f.Close()
+			png.Encode(f, image.NewNRGBA(image.Rect(0, 0, 401, 600)))
+		}, "cover changed"},
+		{"output", func(m *book.Manifest, _ *book.CoverOptions, _ *book.Encoding, _ *cacheEntry) {
+			os.Remove(m.OutputPath())
+		}, "output missing"},
+		{"thumbnail", func(m *book.Manifest, _ *book.CoverOptions, _ *book.Encoding, _ *cacheEntry) {
+			os.Remove(filepath.Join(m.Dir, "test-200.jpg"))
+		}, "cover output missing"},
+	}
+	for _, tt := range tests {
+		m, covers := testLibrary(t)
+		enc := book.Encoding{}
+		c := loadCache(filepath.Join(t.TempDir(), cacheName))
+		if got := c.stale(m, cacheEntry{}, covers.Widths); got != "not built before" {
+			t.Errorf("%s: stale before building = %q", tt.name, got)
+		}
+		last, err := inputs(m, enc, covers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.change(m, &covers, &enc, &last)
+		c.record(m, last)
+		in, err := inputs(m, enc, covers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.stale(m, in, covers.Widths); got != tt.want {
+			t.Errorf("%s: stale = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCachePlaceholderMissing(t *testing.T) {
+	m, covers := testLibrary(t)
+	os.Remove(m.CoverPath())
+	b := m.NewBook()
+	if _, err := book.AddCover(b, m, covers); err != nil {
+		t.Fatal(err)
+	}
+	in, err := inputs(m, book.Encoding{}, covers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := loadCache(filepath.Join(t.TempDir(), cacheName))
+	c.record(m, in)
+	if got := c.stale(m, in, covers.Widths); got != "" {
+		t.Errorf("stale = %q, want up to date", got)
+	}
+	os.Remove(m.PlaceholderPath())
+	if got := c.stale(m, in, covers.Widths); got != "cover output missing" {
+		t.Errorf("stale with the placeholder deleted = %q", got)
+	}
+}
+
+func TestCacheSaveLoad(t *testing.T) {
+	m, covers := testLibrary(t)
+	in, err := inputs(m, book.Encoding{Gzip: true}, covers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), cacheName)
+	c := loadCache(path)
+	c.record(m, in)
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadCache(path).stale(m, in, covers.Widths); got != "" {
+		t.Errorf("stale after reloading = %q, want up to date", got)
+	}
+	os.WriteFile(path, []byte("{not json"), 0o644)
+	if got := loadCache(path).stale(m, in, covers.Widths); got != "not built before" {
+		t.Errorf("stale with an unreadable cache = %q, want everything rebuilt", got)
+	}
+}
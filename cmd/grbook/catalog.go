@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mmccray/GradedReaderBooks/book"
+)
+
+func runCatalog(args []string) error {
+	flags := flag.NewFlagSet("catalog", flag.ExitOnError)
+	output := flags.String("o", book.CatalogName, "write the catalog to this file")
+	flags.Parse(args)
+
+	manifests, err := findBooks(flags.Args())
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("no %s found", book.ManifestName)
+	}
+	return writeCatalog(*output, manifests)
+}
+
+// writeCatalog writes the catalog of the given books to path, shelving them
+// by the collections of the library, and warns about any book it has to
+// leave out.
+func writeCatalog(path string, manifests []*book.Manifest) error {
+	collections, err := book.FindCollections(".")
+	if err != nil {
+		return err
+	}
+	c, errs := book.NewCatalog(filepath.Dir(path), manifests, collections)
+	for _, err := range errs {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if err := book.WriteCatalog(path, c); err != nil {
+		return err
+	}
+	fmt.Printf("Catalog of %d books saved to %s\n", len(manifests)-len(errs), path)
+	return nil
+}
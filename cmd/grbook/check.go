@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mmccray/GradedReaderBooks/book"
+)
+
+// maxDiffs is the number of differences shown for each book.
+const maxDiffs = 20
+
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	update := flags.Bool("update", false, "write the rebuilt books over the committed ones")
+	flags.Parse(args)
+
+	manifests, err := findBooks(flags.Args())
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("no %s found", book.ManifestName)
+	}
+	config, err := book.LoadConfig(".")
+	if err != nil {
+		return err
+	}
+	collections, err := book.FindCollections(".")
+	if err != nil {
+		return err
+	}
+	covers := config.CoverOptions(".", collections)
+	covers.NoWrite = !*update
+	changed := 0
+	for _, m := range manifests {
+		rebuilt, diags, err := book.Convert(m)
+		if err != nil {
+			return err
+		}
+		coverDiags, err := book.AddCover(rebuilt, m, covers)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, coverDiags...)
+		for _, d := range diags {
+			fmt.Printf("Warning: %s\n", d)
+		}
+		path := m.OutputPath()
+		diffs := []string{}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			diffs = append(diffs, "no committed book")
+		} else {
+			committed, err := book.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			diffs = book.Diff(committed, rebuilt)
+		}
+		if len(diffs) == 0 {
+			continue
+		}
+		changed++
+		fmt.Printf("%s:\n", path)
+		for i, d := range diffs {
+			if i == maxDiffs {
+				fmt.Printf("\t... and %d more\n", len(diffs)-maxDiffs)
+				break
+			}
+			fmt.Printf("\t%s\n", d)
+		}
+		if *update {
+			if err := book.WriteFile(path, rebuilt); err != nil {
+				return err
+			}
+			fmt.Printf("Updated %s\n", path)
+		}
+	}
+	if changed > 0 && !*update {
+		return fmt.Errorf("%d of %d books differ from their committed JSON; run grbook check -update to accept the changes", changed, len(manifests))
+	}
+	fmt.Printf("%d books checked\n", len(manifests))
+	return nil
+}
@@ -0,0 +1,131 @@
+// Command grbook converts the plain text sources in this repository into
+// graded reader book JSON.
+//
+// Usage:
+//
+//	grbook build [-force] [-j n] [-compact] [-gzip] [-shard] [-cover-url url] [-thumbs widths] [-catalog file] [dir ...]
+//	grbook catalog [-o file] [dir ...]
+//	grbook normalize [-w] [dir ...]
+//	grbook validate [path ...]
+//	grbook migrate [-n] [path ...]
+//	grbook check [-update] [dir ...]
+//	grbook schema [-o file]
+//
+// Each book directory holds a grbook.json manifest naming the book's
+// metadata, source text and reference scheme. A directory without a
+// manifest is searched recursively, as is the dir of a pattern such as
+// "./...", so "grbook build Plato" converts every dialogue and
+// "grbook build ./..." converts the whole library. Books are converted in
+// parallel by -j workers, and their warnings are reported book by book in
+// library order. Books whose source, manifest and converter are unchanged
+// since they were last built are skipped, using the hashes kept in
+// .grbook-cache.json; -force rebuilds them all.
+//
+// A manifest may instead list the volumes of a work, each a book directory
+// of its own, as ClementOfAlexandria/paidagogos lists the three books of
+// the Paidagogos. The work is built as one book with a part for each
+// volume and refs running on through them, and each volume is still built
+// as a book by itself.
+//
+// Books are written as indented JSON, streamed chapter by chapter. With
+// -compact they are written without indentation or empty glosses, and with
+// each distinct gloss stored once in a table; -gzip writes a compressed
+// copy of each book alongside it. book.ReadFile reads every encoding.
+// With -shard each book is also written as a directory named after its
+// JSON file, holding an index.json of the book's metadata and chapters,
+// with the size and hash of each, and a file per chapter, so that the app
+// can load chapters as they are read.
+//
+// Each book's cover is the image its manifest names, or else a PNG or JPEG
+// in its directory named after its slug. The build checks that the cover
+// can be read and is wide enough, and writes PNG and JPEG thumbnails of it
+// at each of the -thumbs widths beside it, named with the width, as in
+// republic-200.png. The book refers to its cover and thumbnails by path
+// relative to the book file, or with -cover-url by URL: with
+// -cover-url https://raw.githubusercontent.com/mmccray/GradedReaderBooks/main
+// run from the root of the repository, the Republic's cover is
+// .../main/Plato/Republic/republic.png. Books without a cover are reported
+// and given a placeholder instead: an SVG beside the book file, named like
+// it, showing the book's Greek and English titles, author and collection
+// in colours chosen for the collection.
+//
+// The catalog command lists the built books in catalog.json, grouped by
+// collection, with their metadata, cover, size and difficulty level, so
+// that the app can find every book in the library. Every build rewrites
+// the catalog of all the books below the directory grbook runs in.
+//
+// A directory holding a collection.json is a collection, such as Plato or
+// the Apostolic Fathers, and the letters of Ignatius within them. The
+// catalog shelves each book in the innermost collection that holds it, in
+// the order the collection lists its members, with the collection's own
+// metadata; other books are shelved by the collection their manifest
+// names. A collection may also declare an omnibus, which is built as one
+// more book holding each of the collection's books as a part, as
+// ApostolicFathers/Ignatius builds The Seven Letters of Ignatius.
+//
+// Sources are put into normalized form as they are converted. The
+// normalize command lists the source lines that are not already in that
+// form, and with -w rewrites them.
+//
+// The validate command checks book JSON files against the schema of the
+// book format, which the schema command prints and which is published as
+// book.schema.json, and for problems such as repeated chapter slugs, verses
+// out of order and missing images. It exits with status 1 if any file has
+// problems.
+//
+// Every book file records the version of the format it was written in.
+// The migrate command upgrades files written in older versions to the
+// current one.
+//
+// The check command rebuilds each book and compares it with the committed
+// JSON, listing the chapters and verses that differ. It exits with status
+// 1 if any book differs, unless -update is given to write the rebuilt books
+// in place of the committed ones.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: grbook <command> [arguments]\n\n")
+	fmt.Fprintf(os.Stderr, "commands:\n")
+	fmt.Fprintf(os.Stderr, "  build [-force] [-j n] [-compact] [-gzip] [-shard] [-cover-url url] [-thumbs widths]\n        [-catalog file] [dir ...]\n                            convert the books in the given directories\n")
+	fmt.Fprintf(os.Stderr, "  catalog [-o file] [dir ...] write the catalog of the books in the given directories\n")
+	fmt.Fprintf(os.Stderr, "  normalize [-w] [dir ...]  report or rewrite source lines that need normalizing\n")
+	fmt.Fprintf(os.Stderr, "  validate [path ...]       check book JSON files\n")
+	fmt.Fprintf(os.Stderr, "  migrate [-n] [path ...]   upgrade book JSON files to the current schema version\n")
+	fmt.Fprintf(os.Stderr, "  check [-update] [dir ...] compare rebuilt books with the committed JSON\n")
+	fmt.Fprintf(os.Stderr, "  schema [-o file]          print the JSON Schema of the book format\n")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "build":
+		err = runBuild(os.Args[2:])
+	case "catalog":
+		err = runCatalog(os.Args[2:])
+	case "normalize":
+		err = runNormalize(os.Args[2:])
+	case "validate":
+		err = runValidate(os.Args[2:])
+	case "migrate":
+		err = runMigrate(os.Args[2:])
+	case "check":
+		err = runCheck(os.Args[2:])
+	case "schema":
+		err = runSchema(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/mmccray/GradedReaderBooks/book"
+)
+
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := flags.Bool("n", false, "report the migrations without rewriting any file")
+	flags.Parse(args)
+
+	files, err := findBookFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	migrated := 0
+	for _, path := range files {
+		applied, err := book.MigrateFile(path, !*dryRun)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			continue
+		}
+		migrated++
+		fmt.Printf("%s:\n", path)
+		for _, step := range applied {
+			fmt.Printf("\t%s\n", step)
+		}
+	}
+	fmt.Printf("%d of %d book files migrated to schema version %d\n", migrated, len(files), book.SchemaVersion)
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/greek"
+)
+
+func runNormalize(args []string) error {
+	flags := flag.NewFlagSet("normalize", flag.ExitOnError)
+	write := flags.Bool("w", false, "rewrite the source files in normalized form")
+	flags.Parse(args)
+
+	manifests, err := findBooks(flags.Args())
+	if err != nil {
+		return err
+	}
+	for _, m := range manifests {
+		if len(m.Volumes) > 0 {
+			// The volumes are normalized from their own manifests.
+			continue
+		}
+		path := m.SourcePath()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading file: %v", err)
+		}
+		lines := strings.Split(string(content), "\n")
+		changed := 0
+		for i, line := range lines {
+			normalized, steps := greek.Changes(line)
+			if len(steps) == 0 {
+				continue
+			}
+			changed++
+			fmt.Printf("%s:%d: %s\n", path, i+1, strings.Join(steps, ", "))
+			for _, w := range changedWords(line, normalized) {
+				fmt.Printf("\t%s\n", w)
+			}
+			lines[i] = normalized
+		}
+		if changed == 0 || !*write {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+			return fmt.Errorf("error writing file: %v", err)
+		}
+		fmt.Printf("Normalized %d lines of %s\n", changed, path)
+	}
+	return nil
+}
+
+// changedWords lists the words of line that normalizing changes, as
+// "before -> after". Normalization never changes whitespace, so the words
+// of both lines correspond.
+func changedWords(line, normalized string) []string {
+	before, after := strings.Fields(line), strings.Fields(normalized)
+	changes := []string{}
+	for i := range before {
+		if i < len(after) && before[i] != after[i] {
+			changes = append(changes, fmt.Sprintf("%s -> %s", before[i], after[i]))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mmccray/GradedReaderBooks/book"
+)
+
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	flags.Parse(args)
+
+	files, err := findBookFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	problems, failed := 0, 0
+	for _, path := range files {
+		found, err := book.ValidateFile(path)
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			continue
+		}
+		failed++
+		problems += len(found)
+		fmt.Printf("%s:\n", path)
+		for _, p := range found {
+			fmt.Printf("\t%s\n", p)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d problems in %d of %d book files", problems, failed, len(files))
+	}
+	fmt.Printf("%d book files are valid\n", len(files))
+	return nil
+}
+
+// notBook names the JSON files in the library that are not books.
+var notBook = map[string]bool{
+	book.ManifestName:   true,
+	book.CatalogName:    true,
+	book.SchemaName:     true,
+	book.CollectionName: true,
+	book.ConfigName:     true,
+	cacheName:           true,
+}
+
+// findBookFiles returns the given JSON files and every JSON file below the
+// given directories, defaulting to the current directory. Manifests, the
+// catalog, the schema itself and sharded books are not book files and are
+// left out.
+func findBookFiles(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files := []string{}
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && path != root && d.Name()[0] == '.' {
+				return filepath.SkipDir
+			}
+			if d.IsDir() && path != root {
+				// The chapters of a sharded book are not books.
+				if _, err := os.Stat(filepath.Join(path, book.IndexName)); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") && !notBook[d.Name()] {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
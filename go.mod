@@ -0,0 +1,5 @@
+module github.com/mmccray/GradedReaderBooks
+
+go 1.22
+
+require golang.org/x/text v0.22.0
@@ -0,0 +1,133 @@
+package greek
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// A step is one stage of normalization, named for reports.
+type step struct {
+	name string
+	fn   func(string) string
+}
+
+// steps are applied in order by Normalize.
+var steps = []step{
+	{"oxia", oxiaToTonos},
+	{"decomposed", norm.NFC.String},
+	{"lookalike", lookalikes},
+	{"elision mark", elisions},
+	{"final sigma", finalSigma},
+}
+
+// Normalize puts Greek text into the canonical form used throughout the
+// library, so that the same word is always spelled with the same bytes:
+// NFC with tonos rather than oxia accents, ";" and "·" for the Greek
+// question mark and ano teleia, Greek letters in place of the symbols that
+// resemble them, ’ for every elision mark and ς only at the end of a word.
+func Normalize(s string) string {
+	for _, st := range steps {
+		s = st.fn(s)
+	}
+	return s
+}
+
+// Changes normalizes s and also names the steps that changed it, such as
+// "oxia" or "final sigma".
+func Changes(s string) (string, []string) {
+	names := []string{}
+	for _, st := range steps {
+		n := st.fn(s)
+		if n != s {
+			names = append(names, st.name)
+		}
+		s = n
+	}
+	return s, names
+}
+
+// oxia maps the letters with oxia in the Greek Extended block to the same
+// letters with tonos, which Unicode treats as equivalent.
+var oxia = strings.NewReplacer(
+	"\u1f71", "\u03ac", "\u1f73", "\u03ad", "\u1f75", "\u03ae", "\u1f77", "\u03af",
+	"\u1f79", "\u03cc", "\u1f7b", "\u03cd", "\u1f7d", "\u03ce", "\u1fbb", "\u0386",
+	"\u1fc9", "\u0388", "\u1fcb", "\u0389", "\u1fdb", "\u038a", "\u1ff9", "\u038c",
+	"\u1feb", "\u038e", "\u1ffb", "\u038f", "\u1fd3", "\u0390", "\u1fe3", "\u03b0",
+)
+
+func oxiaToTonos(s string) string {
+	return oxia.Replace(s)
+}
+
+// lookalike maps symbols that the sources use in place of Greek letters,
+// such as the micro sign for μ.
+var lookalike = strings.NewReplacer(
+	"\u00b5", "\u03bc", // MICRO SIGN
+	"\u2206", "\u0394", // INCREMENT
+	"\u2126", "\u03a9", // OHM SIGN
+	"\u03d0", "\u03b2", // GREEK BETA SYMBOL
+)
+
+func lookalikes(s string) string {
+	return lookalike.Replace(s)
+}
+
+// elisions writes every elision mark that follows a Greek letter as
+// Apostrophe.
+func elisions(s string) string {
+	r := []rune(s)
+	for i := 1; i < len(r); i++ {
+		if IsElision(r[i]) && r[i] != Apostrophe && isGreekLetter(r[i-1]) {
+			r[i] = Apostrophe
+		}
+	}
+	return string(r)
+}
+
+// finalSigma writes σ at the end of a word as ς and ς inside a word as σ.
+// A sigma before an elision mark or an editorial bracket, as in "γλῶσσ’"
+// or "τοῖσ<ι>", is left as the source has it.
+func finalSigma(s string) string {
+	r := []rune(s)
+	for i, c := range r {
+		if c != 'σ' && c != 'ς' || i == 0 || !isGreekLetter(r[i-1]) {
+			continue
+		}
+		if i+1 == len(r) || endsWord(r[i+1]) {
+			r[i] = 'ς'
+		} else if isGreekLetter(r[i+1]) || unicode.Is(unicode.Mn, r[i+1]) {
+			r[i] = 'σ'
+		}
+	}
+	return string(r)
+}
+
+// endsWord reports whether r can only follow the last letter of a word.
+func endsWord(r rune) bool {
+	if unicode.IsSpace(r) {
+		return true
+	}
+	return IsPunct(r) && !IsElision(r) && !strings.ContainsRune("[]<>()⟨⟩", r)
+}
+
+func isGreekLetter(r rune) bool {
+	return unicode.Is(unicode.Greek, r) && unicode.IsLetter(r)
+}
+
+// Fold reduces a word to the form used to count distinct words: lower
+// case, without accents or breathings, and with σ for ς.
+func Fold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if r == 'ς' {
+			r = 'σ'
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFC.String(b.String())
+}
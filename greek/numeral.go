@@ -0,0 +1,148 @@
+package greek
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Keraia is the mark written after a Greek numeral, as in "ιβʹ" for 12. It
+// is U+02B9, which is what NFC makes of the Greek numeral sign U+0374.
+const Keraia = 'ʹ'
+
+// LowerKeraia is the mark written before a letter to multiply it by a
+// thousand, as in "͵α" for 1000.
+const LowerKeraia = '͵'
+
+// numeralLetters are the letters of each place of a numeral, for the
+// digits 1 to 9: stigma for 6, koppa for 90 and sampi for 900.
+var numeralLetters = [3][9]rune{
+	{'α', 'β', 'γ', 'δ', 'ε', 'ϛ', 'ζ', 'η', 'θ'},
+	{'ι', 'κ', 'λ', 'μ', 'ν', 'ξ', 'ο', 'π', 'ϟ'},
+	{'ρ', 'σ', 'τ', 'υ', 'φ', 'χ', 'ψ', 'ω', 'ϡ'},
+}
+
+// numeralValues maps each letter a numeral may be written with to its
+// value, including the variants sources use: digamma or ς for stigma, the
+// archaic koppa and the archaic sampi.
+var numeralValues = map[rune]int{
+	'ϝ': 6, 'ς': 6, 'ϙ': 90, 'ͳ': 900,
+}
+
+// keraiaMarks are the marks sources write for the keraia: Keraia, the
+// numeral sign it stands for, the tonos and the prime.
+var keraiaMarks = map[rune]bool{
+	Keraia: true, '\u0374': true, '΄': true, '′': true,
+}
+
+func init() {
+	for place, letters := range numeralLetters {
+		scale := []int{1, 10, 100}[place]
+		for i, r := range letters {
+			numeralValues[r] = (i + 1) * scale
+		}
+	}
+}
+
+// Numeral writes n as a Greek numeral with its keraia, as in "ιαʹ" for 11
+// or "͵αωλγʹ" for 1833. Numbers outside 1 to 9999, which need the
+// myriad notation, are returned in Arabic numerals.
+func Numeral(n int) string {
+	if n < 1 || n > 9999 {
+		return strconv.Itoa(n)
+	}
+	var b strings.Builder
+	if n >= 1000 {
+		b.WriteRune(LowerKeraia)
+		b.WriteRune(numeralLetters[0][n/1000-1])
+	}
+	b.WriteString(string(letters(n % 1000)))
+	b.WriteRune(Keraia)
+	return b.String()
+}
+
+// letters returns the letters of n, from 0 to 999, hundreds first.
+func letters(n int) []rune {
+	rs := []rune{}
+	for place := 2; place >= 0; place-- {
+		digit := n / []int{1, 10, 100}[place] % 10
+		if digit > 0 {
+			rs = append(rs, numeralLetters[place][digit-1])
+		}
+	}
+	return rs
+}
+
+// ParseNumeral reads a Greek numeral such as "ιβʹ", "Α΄" or "͵ασʹ". The
+// keraia may be left off, and the letters may be upper or lower case. The
+// letters must run from the largest place to the smallest, each place
+// written at most once, and only a unit may take the lower keraia, so that
+// numerals run from 1 to 9999.
+func ParseNumeral(s string) (int, error) {
+	rs := []rune(strings.TrimSpace(s))
+	if n := len(rs); n > 0 && keraiaMarks[rs[n-1]] {
+		rs = rs[:n-1]
+	}
+	if len(rs) == 0 {
+		return 0, fmt.Errorf("invalid Greek numeral %q", s)
+	}
+	n := 0
+	last := 10000 // the value of the last place written
+	for i := 0; i < len(rs); i++ {
+		scale := 1
+		if rs[i] == LowerKeraia {
+			scale = 1000
+			if i++; i == len(rs) {
+				return 0, fmt.Errorf("invalid Greek numeral %q", s)
+			}
+		}
+		v, ok := numeralValues[unicode.ToLower(rs[i])]
+		if !ok {
+			return 0, fmt.Errorf("invalid Greek numeral %q: %q is not a numeral letter", s, rs[i])
+		}
+		if scale == 1000 && v >= 10 {
+			return 0, fmt.Errorf("invalid Greek numeral %q: only a unit may be thousands", s)
+		}
+		v *= scale
+		place := placeOf(v)
+		if place >= last {
+			return 0, fmt.Errorf("invalid Greek numeral %q: letters out of order", s)
+		}
+		last = place
+		n += v
+	}
+	return n, nil
+}
+
+// placeOf returns the place of a digit's value: 1 for the units, 10 for
+// the tens and so on.
+func placeOf(v int) int {
+	place := 1
+	for v >= place*10 {
+		place *= 10
+	}
+	return place
+}
+
+// SplitNumeral splits a heading that ends in a Greek numeral, such as
+// "Ὅρασις αʹ", into the words before it and its value. Only a numeral
+// marked with a keraia, or with the lower keraia, is taken for one, so
+// that a heading ending in an ordinary word is not read as numbered.
+func SplitNumeral(heading string) (string, int, bool) {
+	heading = strings.TrimSpace(heading)
+	i := strings.LastIndexFunc(heading, unicode.IsSpace)
+	word := heading[i+1:]
+	rs := []rune(word)
+	if len(rs) < 2 || !keraiaMarks[rs[len(rs)-1]] && rs[0] != LowerKeraia {
+		return "", 0, false
+	}
+	n, err := ParseNumeral(word)
+	if err != nil {
+		return "", 0, false
+	}
+	if i < 0 {
+		return "", n, true
+	}
+	return strings.TrimSpace(heading[:i]), n, true
+}
@@ -0,0 +1,126 @@
+package greek
+
+import "testing"
+
+func TestNumeral(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "αʹ"},
+		{6, "ϛʹ"},
+		{11, "ιαʹ"},
+		{16, "ιϛʹ"},
+		{90, "ϟʹ"},
+		{99, "ϟθʹ"},
+		{100, "ρʹ"},
+		{666, "χξϛʹ"},
+		{900, "ϡʹ"},
+		{999, "ϡϟθʹ"},
+		{1000, "͵αʹ"},
+		{1833, "͵αωλγʹ"},
+		{9999, "͵θϡϟθʹ"},
+		{10000, "10000"},
+		{12345, "12345"},
+		{0, "0"},
+		{-3, "-3"},
+		{1000000, "1000000"},
+	}
+	for _, tt := range tests {
+		if got := Numeral(tt.n); got != tt.want {
+			t.Errorf("Numeral(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestNumeralRoundTrip(t *testing.T) {
+	for n := 1; n <= 9999; n++ {
+		s := Numeral(n)
+		got, err := ParseNumeral(s)
+		if err != nil || got != n {
+			t.Fatalf("ParseNumeral(Numeral(%d) = %q) = %d, %v", n, s, got, err)
+		}
+	}
+}
+
+func TestParseNumeral(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"αʹ", 1},
+		{"α\u0374", 1},
+		{"Α΄", 1},
+		{"Β′", 2},
+		{"ιβ", 12},
+		{"ΙΒʹ", 12},
+		{"ϛʹ", 6},
+		{"ϝʹ", 6},
+		{"ςʹ", 6},
+		{"ϟʹ", 90},
+		{"ϙʹ", 90},
+		{"ϡʹ", 900},
+		{"ͳʹ", 900},
+		{"͵α", 1000},
+		{"͵ασʹ", 1200},
+		{" κγʹ ", 23},
+	}
+	for _, tt := range tests {
+		got, err := ParseNumeral(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseNumeral(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseNumeralErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"ʹ",
+		"0",
+		"-αʹ",
+		"12",
+		"αβʹ",  // two units
+		"ιιʹ",  // two tens
+		"αιʹ",  // units before tens
+		"͵",    // thousands sign without a letter
+		"α͵βʹ", // thousands after units
+		"͵ιʹ",  // ten thousand
+		"͵ι͵βτμεʹ",
+		"xʹ",
+		"λόγος",
+	} {
+		if n, err := ParseNumeral(in); err == nil {
+			t.Errorf("ParseNumeral(%q) = %d, want an error", in, n)
+		}
+	}
+}
+
+func TestSplitNumeral(t *testing.T) {
+	tests := []struct {
+		in   string
+		word string
+		n    int
+		ok   bool
+	}{
+		{"Ὅρασις αʹ", "Ὅρασις", 1, true},
+		{"Ἀποκάλυψις εʹ", "Ἀποκάλυψις", 5, true},
+		{"Ἐντολὴ ιβʹ", "Ἐντολὴ", 12, true},
+		{"Παραβολὴ ϛʹ", "Παραβολὴ", 6, true},
+		{"Παιδαγωγός Α΄", "Παιδαγωγός", 1, true},
+		{"Κεφάλαιον ͵α", "Κεφάλαιον", 1000, true},
+		{"ιαʹ", "", 11, true},
+		{"Ἄλλη παραβολή", "", 0, false},
+		{"ΠΑΡΑΒΟΛΑΙ ΑΣ ΕΛΑΛΗΣΕ ΜΕΤ’ ΕΜΟΥ", "", 0, false},
+		{"Similitudo X", "", 0, false},
+		{"Ὅρασις αβʹ", "", 0, false},
+		{"Ὅρασις", "", 0, false},
+		{"", "", 0, false},
+	}
+	for _, tt := range tests {
+		word, n, ok := SplitNumeral(tt.in)
+		if word != tt.word || n != tt.n || ok != tt.ok {
+			t.Errorf("SplitNumeral(%q) = %q, %d, %t, want %q, %d, %t", tt.in, word, n, ok, tt.word, tt.n, tt.ok)
+		}
+	}
+}
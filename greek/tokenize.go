@@ -0,0 +1,125 @@
+// Package greek holds the text handling specific to Ancient Greek: splitting
+// verses into words and their punctuation, and reading and writing Greek
+// numerals.
+package greek
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Apostrophe is the elision mark every elided word is given in its clean
+// form, whichever mark the source used.
+const Apostrophe = '’'
+
+// elisionMarks are the marks the sources use for elision, as in "δ’", "ἀλλ᾽"
+// and "ἐπ'".
+var elisionMarks = map[rune]bool{
+	'’':  true,
+	'᾽':  true, // U+1FBD GREEK KORONIS
+	'\'': true,
+	'ʼ':  true, // U+02BC MODIFIER LETTER APOSTROPHE
+	'᾿':  true, // U+1FBF GREEK PSILI
+}
+
+// IsElision reports whether r is one of the marks used for elision.
+func IsElision(r rune) bool {
+	return elisionMarks[r]
+}
+
+// IsPunct reports whether r is punctuation that can lead or trail a word.
+// Besides ordinary punctuation this covers the Greek question mark (U+037E),
+// the ano teleia (U+0387 and U+00B7), guillemets and dashes.
+func IsPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r) || IsElision(r)
+}
+
+// Token is one word of a text together with the punctuation around it.
+// Form is the word alone, with any elision mark replaced by Apostrophe;
+// Elision keeps the mark the source used when it was a different one.
+// A token whose Form is empty is punctuation standing on its own, held in
+// Pre.
+type Token struct {
+	Space   string // whitespace before the token
+	Pre     string // leading punctuation, such as "«"
+	Form    string
+	Elision string
+	Post    string // trailing punctuation, such as "," or "·"
+}
+
+// Tokenize splits s into tokens at whitespace. Render(Tokenize(s)) == s.
+func Tokenize(s string) []Token {
+	tokens := []Token{}
+	for len(s) > 0 {
+		rest := strings.TrimLeftFunc(s, unicode.IsSpace)
+		if rest == "" {
+			// Trailing whitespace is kept on an empty token so that
+			// nothing is lost.
+			tokens = append(tokens, Token{Space: s})
+			break
+		}
+		space := s[:len(s)-len(rest)]
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		t := split(rest[:end])
+		t.Space = space
+		tokens = append(tokens, t)
+		s = rest[end:]
+	}
+	return tokens
+}
+
+// split divides a run of non-space characters into a token.
+func split(chunk string) Token {
+	r := []rune(chunk)
+	i := 0
+	for i < len(r) && IsPunct(r[i]) {
+		i++
+	}
+	if i == len(r) {
+		return Token{Pre: chunk}
+	}
+	j := len(r)
+	for j > i && IsPunct(r[j-1]) {
+		// A mark straight after a letter is elision and belongs to the
+		// word.
+		if IsElision(r[j-1]) && j-1 > i && unicode.IsLetter(r[j-2]) {
+			break
+		}
+		j--
+	}
+	t := Token{Pre: string(r[:i]), Post: string(r[j:])}
+	form := r[i:j]
+	if last := form[len(form)-1]; IsElision(last) && last != Apostrophe {
+		t.Elision = string(last)
+		form[len(form)-1] = Apostrophe
+	}
+	t.Form = string(form)
+	return t
+}
+
+// Written returns the word as the source wrote it, with its original
+// elision mark.
+func (t Token) Written() string {
+	if t.Elision == "" {
+		return t.Form
+	}
+	return strings.TrimSuffix(t.Form, string(Apostrophe)) + t.Elision
+}
+
+// String returns the token as written, without the whitespace before it.
+func (t Token) String() string {
+	return t.Pre + t.Written() + t.Post
+}
+
+// Render joins tokens back into the text they were read from.
+func Render(tokens []Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.Space)
+		b.WriteString(t.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package greek
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Token
+	}{
+		{"λέγει", []Token{{Form: "λέγει"}}},
+		{"«Οὐδεὶς ἔγνω»", []Token{
+			{Pre: "«", Form: "Οὐδεὶς"},
+			{Space: " ", Form: "ἔγνω", Post: "»"},
+		}},
+		{"ἔφη· καὶ", []Token{
+			{Form: "ἔφη", Post: "·"},
+			{Space: " ", Form: "καὶ"},
+		}},
+		{"τί λέγεις;", []Token{
+			{Form: "τί"},
+			{Space: " ", Form: "λέγεις", Post: ";"},
+		}},
+		{"δ’ οὖν", []Token{
+			{Form: "δ’"},
+			{Space: " ", Form: "οὖν"},
+		}},
+		{"ἀλλ᾽", []Token{{Form: "ἀλλ’", Elision: "᾽"}}},
+		{"ἐπ᾿", []Token{{Form: "ἐπ’", Elision: "᾿"}}},
+		{"ἐπ'", []Token{{Form: "ἐπ’", Elision: "'"}}},
+		{"«ἀλλ᾽,", []Token{{Pre: "«", Form: "ἀλλ’", Elision: "᾽", Post: ","}}},
+		{"λόγος — ὁ", []Token{
+			{Form: "λόγος"},
+			{Space: " ", Pre: "—"},
+			{Space: " ", Form: "ὁ"},
+		}},
+		{"«…»", []Token{{Pre: "«…»"}}},
+		{"ναί. \n", []Token{
+			{Form: "ναί", Post: "."},
+			{Space: " \n"},
+		}},
+		{"", []Token{}},
+	}
+	for _, tt := range tests {
+		got := Tokenize(tt.in)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []string{
+		"",
+		"  ",
+		"λέγει",
+		" «Οὐδεὶς ἔγνω τὸν πατέρα εἰ μὴ ὁ υἱός.»",
+		"ἔφη· τί λέγεις;",
+		"ἀλλ᾽ ἐπ᾿ αὐτῷ, κατ' ἐμέ, δ’ οὖν",
+		"λόγος — ὁ ( ) ...",
+		"ναί. \t\n",
+		"\nἐν ἀρχῇ\n\n",
+	}
+	for _, s := range tests {
+		if got := Render(Tokenize(s)); got != s {
+			t.Errorf("Render(Tokenize(%q)) = %q", s, got)
+		}
+	}
+}